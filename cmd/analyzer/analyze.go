@@ -0,0 +1,20 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	input "analyzer/internal/infrastructure/input"
+)
+
+func runAnalyze(args []string) {
+	requestTemplate := input.RequestTemplate{
+		RequiredFlags: []string{"path"},
+		OptionalFlags: analyzeFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	parser := parsers.NewParserRequest()
+	config := parser.Parse(request)
+
+	newApp().Run(&config)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	server "analyzer/internal/application/server"
+	domain "analyzer/internal/domain"
+	input "analyzer/internal/infrastructure/input"
+	"log"
+)
+
+var dashboardFlags = []string{
+	"path", "syslog", "listen", "refresh", "max-records", "timeout", "from", "to", "filter-field", "filter-value",
+	"normalize", "strip-query", "keep-query", "routes", "ua-rules", "site-host", "bucket",
+	"session-gap", "funnel", "funnel-window", "anomaly-threshold", "anomaly-window", "security-rules", "geoip-db",
+	"trusted-proxies", "client-ip", "anonymize", "anonymize-key", "redact-params", "approx", "workers",
+}
+
+func runDashboard(args []string) {
+	requestTemplate := input.RequestTemplate{
+		OptionalFlags: dashboardFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	if request["path"] == "" && request["syslog"] == "" {
+		log.Fatalf("Ошибка: укажите --path или --syslog")
+	}
+
+	if request["listen"] == "" {
+		request["listen"] = domain.DefaultDashboardListen
+	}
+
+	request["format"] = domain.JSONFormat
+
+	parser := parsers.NewParserRequest()
+	config := parser.ParseServe(request)
+
+	log.Printf("Панель доступна по адресу http://%s/", config.Serve.Listen)
+
+	err := server.NewDashboard(newApp(), &config).Run()
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
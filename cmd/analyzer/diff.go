@@ -0,0 +1,27 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	input "analyzer/internal/infrastructure/input"
+	"maps"
+)
+
+func runDiff(args []string) {
+	requestTemplate := input.RequestTemplate{
+		RequiredFlags: []string{"base", "current"},
+		OptionalFlags: analyzeFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+	parser := parsers.NewParserRequest()
+
+	baseRequest := maps.Clone(request)
+	baseRequest["path"] = request["base"]
+	baseConfig := parser.Parse(baseRequest)
+
+	currentRequest := maps.Clone(request)
+	currentRequest["path"] = request["current"]
+	currentConfig := parser.Parse(currentRequest)
+
+	newApp().RunDiff(&baseConfig, &currentConfig)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	input "analyzer/internal/infrastructure/input"
+	"log"
+)
+
+var exportFlags = []string{
+	"mode", "row-group", "partition", "from", "until", "filter-field", "filter-value", "normalize", "strip-query", "keep-query",
+	"routes", "ua-rules", "trusted-proxies", "client-ip", "anonymize", "anonymize-key", "redact-params", "geoip-db", "workers",
+}
+
+func runExport(args []string) {
+	outputs, args := input.SplitPositional(args)
+	if len(outputs) != 1 {
+		log.Fatalf("Ошибка: укажите один файл или каталог для экспорта: analyzer export --to sqlite out.db --path ...")
+	}
+
+	requestTemplate := input.RequestTemplate{
+		RequiredFlags: []string{"path", "to"},
+		OptionalFlags: exportFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	request["export"] = request["to"]
+	request["output"] = outputs[0]
+	request["to"] = request["until"]
+
+	parser := parsers.NewParserRequest()
+	config := parser.Parse(request)
+
+	newApp().RunExport(&config)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	server "analyzer/internal/application/server"
+	domain "analyzer/internal/domain"
+	input "analyzer/internal/infrastructure/input"
+	"log"
+)
+
+var exporterFlags = []string{
+	"path", "syslog", "listen", "refresh", "timeout", "label-limits", "from", "to", "filter-field", "filter-value",
+	"normalize", "strip-query", "keep-query", "routes", "ua-rules", "geoip-db", "trusted-proxies", "client-ip", "workers",
+}
+
+func runExporter(args []string) {
+	requestTemplate := input.RequestTemplate{
+		OptionalFlags: exporterFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	if request["path"] == "" && request["syslog"] == "" {
+		log.Fatalf("Ошибка: укажите --path или --syslog")
+	}
+
+	if request["listen"] == "" {
+		request["listen"] = domain.DefaultExporterListen
+	}
+
+	parser := parsers.NewParserRequest()
+	config := parser.ParseServe(request)
+
+	if config.Path != "" && config.TypePath != "local" {
+		log.Fatalf("Ошибка: exporter читает только локальные файлы логов")
+	}
+
+	err := server.NewMetricsServer(newApp(), &config).Run()
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	input "analyzer/internal/infrastructure/input"
+)
+
+var ingestFlags = []string{
+	"from", "to", "filter-field", "filter-value", "normalize", "strip-query", "keep-query", "routes",
+	"trusted-proxies", "client-ip", "anonymize", "anonymize-key", "redact-params", "workers", "checkpoint",
+	"retention", "downsample",
+}
+
+func runIngest(args []string) {
+	requestTemplate := input.RequestTemplate{
+		RequiredFlags: []string{"path", "store"},
+		OptionalFlags: ingestFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	parser := parsers.NewParserRequest()
+	config := parser.Parse(request)
+
+	newApp().RunIngest(&config)
+}
@@ -3,31 +3,72 @@ package main
 import (
 	application "analyzer/internal/application"
 	analyzer "analyzer/internal/application/analyzer"
+	anonymizer "analyzer/internal/application/anonymizer"
+	differ "analyzer/internal/application/differ"
+	enricher "analyzer/internal/application/enricher"
+	exporter "analyzer/internal/application/exporter"
 	filter "analyzer/internal/application/filter"
 	formatter "analyzer/internal/application/formatter"
+	loader "analyzer/internal/application/loader"
+	normalizer "analyzer/internal/application/normalizer"
 	parsers "analyzer/internal/application/parsers"
+	rollupstore "analyzer/internal/application/rollupstore"
 	saver "analyzer/internal/application/saver"
+	statestore "analyzer/internal/application/statestore"
 	input "analyzer/internal/infrastructure/input"
+	"log"
+	"os"
 )
 
-func main() {
-	requestTemplate := input.RequestTemplate{
-		RequiredFlags: []string{"path"},
-		OptionalFlags: []string{"from", "to", "format", "filter-field", "filter-value"},
-	}
-
-	request := input.Request(requestTemplate)
+var analyzeFlags = []string{
+	"from", "to", "format", "filter-field", "filter-value",
+	"normalize", "strip-query", "keep-query", "routes", "ua-rules", "site-host", "bucket",
+	"session-gap", "funnel", "funnel-window", "anomaly-threshold", "anomaly-window", "security-rules", "geoip-db",
+	"trusted-proxies", "client-ip", "anonymize", "anonymize-key", "redact-params",
+	"profile-client", "approx", "workers", "emit-state", "checkpoint",
+}
 
-	parser := parsers.NewParserRequest()
-	config := parser.Parse(request)
+func main() {
+	command, args := input.Command(os.Args[1:])
 
-	app := application.AnalyzerApp{
-		LogParser:   parsers.NewLogParser(),
-		LogAnalyzer: analyzer.NewLogAnalyzer(),
-		LogFilter:   filter.NewLogFilter(),
-		Formatter:   formatter.NewFormatter(),
-		Saver:       saver.NewSaver(),
+	switch command {
+	case "", "analyze":
+		runAnalyze(args)
+	case "diff":
+		runDiff(args)
+	case "merge":
+		runMerge(args)
+	case "ingest":
+		runIngest(args)
+	case "query":
+		runQuery(args)
+	case "export":
+		runExport(args)
+	case "serve":
+		runServe(args)
+	case "dashboard":
+		runDashboard(args)
+	case "exporter":
+		runExporter(args)
+	default:
+		log.Fatalf("Ошибка: неизвестная команда: %s", command)
 	}
+}
 
-	app.Run(&config)
+func newApp() *application.AnalyzerApp {
+	return &application.AnalyzerApp{
+		LogParser:     parsers.NewLogParser(),
+		URLNormalizer: normalizer.NewURLNormalizer(),
+		LogEnricher:   enricher.NewLogEnricher(),
+		LogAnalyzer:   analyzer.NewLogAnalyzer(),
+		LogFilter:     filter.NewLogFilter(),
+		LogAnonymizer: anonymizer.NewLogAnonymizer(),
+		ReportLoader:  loader.NewReportLoader(),
+		StateStore:    statestore.NewStateStore(),
+		RollupStore:   rollupstore.NewRollupStore(),
+		Exporter:      exporter.NewExporter(),
+		ReportDiffer:  differ.NewReportDiffer(),
+		Formatter:     formatter.NewFormatter(),
+		Saver:         saver.NewSaver(),
+	}
 }
@@ -0,0 +1,23 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	input "analyzer/internal/infrastructure/input"
+)
+
+var mergeFlags = []string{"format", "emit-state", "security-rules", "anomaly-threshold", "anomaly-window"}
+
+func runMerge(args []string) {
+	paths, args := input.Positional(args)
+
+	requestTemplate := input.RequestTemplate{
+		OptionalFlags: mergeFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	parser := parsers.NewParserRequest()
+	config := parser.ParseMerge(paths, request)
+
+	newApp().Run(&config)
+}
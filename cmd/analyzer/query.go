@@ -0,0 +1,22 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	input "analyzer/internal/infrastructure/input"
+)
+
+var queryFlags = []string{"from", "to", "format", "bucket", "anomaly-threshold", "anomaly-window"}
+
+func runQuery(args []string) {
+	requestTemplate := input.RequestTemplate{
+		RequiredFlags: []string{"store"},
+		OptionalFlags: queryFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	parser := parsers.NewParserRequest()
+	config := parser.ParseQuery(request)
+
+	newApp().RunQuery(&config)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	parsers "analyzer/internal/application/parsers"
+	server "analyzer/internal/application/server"
+	domain "analyzer/internal/domain"
+	input "analyzer/internal/infrastructure/input"
+	"log"
+)
+
+var serveFlags = []string{
+	"path", "syslog", "refresh", "max-records", "timeout", "max-upload", "from", "to", "format", "filter-field", "filter-value",
+	"normalize", "strip-query", "keep-query", "routes", "ua-rules", "site-host", "bucket",
+	"session-gap", "funnel", "funnel-window", "anomaly-threshold", "anomaly-window", "security-rules", "geoip-db",
+	"trusted-proxies", "client-ip", "anonymize", "anonymize-key", "redact-params", "profile-client", "approx", "workers",
+}
+
+func runServe(args []string) {
+	requestTemplate := input.RequestTemplate{
+		RequiredFlags: []string{"listen"},
+		OptionalFlags: serveFlags,
+	}
+
+	request := input.Request(requestTemplate, args)
+
+	if request["format"] == "" {
+		request["format"] = domain.JSONFormat
+	}
+
+	parser := parsers.NewParserRequest()
+	config := parser.ParseServe(request)
+
+	err := server.NewServer(newApp(), &config).Run()
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+const (
+	topLimit = 3
+
+	sizePrecisionBits = 7
+)
+
+type accumulator interface {
+	add(record *domain.LogRecord)
+	fields() []any
+	merge(other accumulator)
+	fill(report *domain.LogReport)
+}
+
+type generalAccumulator struct {
+	approx        bool
+	count         int
+	totalBodySize int
+	bodySizes     map[int]int
+	firstTime     time.Time
+	lastTime      time.Time
+}
+
+func newGeneralAccumulator(capacity int) *generalAccumulator {
+	return &generalAccumulator{approx: capacity > 0, bodySizes: make(map[int]int)}
+}
+
+func (acc *generalAccumulator) add(record *domain.LogRecord) {
+	if acc.count == 0 || record.TimeLocal.Before(acc.firstTime) {
+		acc.firstTime = record.TimeLocal
+	}
+
+	if acc.count == 0 || record.TimeLocal.After(acc.lastTime) {
+		acc.lastTime = record.TimeLocal
+	}
+
+	acc.count++
+	acc.totalBodySize += record.BodyBytesSent
+
+	if acc.approx {
+		acc.bodySizes[roundSize(record.BodyBytesSent)]++
+	} else {
+		acc.bodySizes[record.BodyBytesSent]++
+	}
+}
+
+func roundSize(size int) int {
+	shift := bits.Len(uint(size)) - sizePrecisionBits
+	if shift <= 0 {
+		return size
+	}
+
+	return size >> shift << shift
+}
+
+func (acc *generalAccumulator) fields() []any {
+	return []any{&acc.count, &acc.totalBodySize, &acc.bodySizes, &acc.firstTime, &acc.lastTime}
+}
+
+func (acc *generalAccumulator) merge(other accumulator) {
+	source := other.(*generalAccumulator)
+
+	if source.count == 0 {
+		return
+	}
+
+	if acc.count == 0 || source.firstTime.Before(acc.firstTime) {
+		acc.firstTime = source.firstTime
+	}
+
+	if acc.count == 0 || source.lastTime.After(acc.lastTime) {
+		acc.lastTime = source.lastTime
+	}
+
+	acc.count += source.count
+	acc.totalBodySize += source.totalBodySize
+	mergeCounts(acc.bodySizes, source.bodySizes)
+}
+
+func (acc *generalAccumulator) fill(report *domain.LogReport) {
+	if acc.count == 0 {
+		return
+	}
+
+	if acc.count > 1 {
+		report.AvgTimeBetweenRequests = acc.lastTime.Sub(acc.firstTime) / time.Duration(acc.count-1)
+	}
+
+	report.FirstRequest = acc.firstTime
+	report.LastRequest = acc.lastTime
+	report.AvgBodySize = acc.totalBodySize / acc.count
+	report.Percentile95Size = calculatePercentile(acc.bodySizes, acc.count, 95)
+}
+
+type resourceAccumulator struct {
+	resources counter
+}
+
+func newResourceAccumulator(capacity int) *resourceAccumulator {
+	return &resourceAccumulator{resources: newCounter(capacity)}
+}
+
+func (acc *resourceAccumulator) add(record *domain.LogRecord) {
+	acc.resources.add(record.URL, 1)
+}
+
+func (acc *resourceAccumulator) fields() []any {
+	return []any{&counterState{acc.resources}}
+}
+
+func (acc *resourceAccumulator) merge(other accumulator) {
+	acc.resources.merge(other.(*resourceAccumulator).resources)
+}
+
+func (acc *resourceAccumulator) fill(report *domain.LogReport) {
+	report.RequestedResources = acc.resources.counts()
+	report.SortedRequestedResources = sortRequestedResources(report.RequestedResources)
+	report.Approximation.ResourceError = acc.resources.maxError()
+}
+
+type responseCodeAccumulator struct {
+	statusCodes map[int]string
+	codes       map[int]domain.ResponseCode
+}
+
+func newResponseCodeAccumulator(statusCodes map[int]string) *responseCodeAccumulator {
+	return &responseCodeAccumulator{
+		statusCodes: statusCodes,
+		codes:       make(map[int]domain.ResponseCode),
+	}
+}
+
+func (acc *responseCodeAccumulator) add(record *domain.LogRecord) {
+	if responseCode, exists := acc.codes[record.Status]; exists {
+		responseCode.Count++
+		acc.codes[record.Status] = responseCode
+	} else {
+		acc.codes[record.Status] = domain.ResponseCode{
+			Name:  acc.statusCodes[record.Status],
+			Count: 1,
+		}
+	}
+}
+
+func (acc *responseCodeAccumulator) fields() []any {
+	return []any{&acc.codes}
+}
+
+func (acc *responseCodeAccumulator) merge(other accumulator) {
+	for status, source := range other.(*responseCodeAccumulator).codes {
+		responseCode := acc.codes[status]
+		responseCode.Name = source.Name
+		responseCode.Count += source.Count
+		acc.codes[status] = responseCode
+	}
+}
+
+func (acc *responseCodeAccumulator) fill(report *domain.LogReport) {
+	report.ResponseCodes = acc.codes
+	report.SortedResponseCodes = sortResponseCodes(acc.codes)
+}
+
+type ipAccumulator struct {
+	ipRequests counter
+}
+
+func newIPAccumulator(capacity int) *ipAccumulator {
+	return &ipAccumulator{ipRequests: newCounter(capacity)}
+}
+
+func (acc *ipAccumulator) add(record *domain.LogRecord) {
+	acc.ipRequests.add(record.RemoteAddr, 1)
+}
+
+func (acc *ipAccumulator) fields() []any {
+	return []any{&counterState{acc.ipRequests}}
+}
+
+func (acc *ipAccumulator) merge(other accumulator) {
+	acc.ipRequests.merge(other.(*ipAccumulator).ipRequests)
+}
+
+func (acc *ipAccumulator) fill(report *domain.LogReport) {
+	for _, value := range topValues(acc.ipRequests.counts(), topLimit) {
+		report.TopIPAddresses = append(report.TopIPAddresses, domain.IPCount{IP: value.Value, Count: value.Count})
+	}
+
+	report.Approximation.IPError = acc.ipRequests.maxError()
+}
+
+func calculatePercentile(values map[int]int, total, percentile int) int {
+	sortedValues := make([]int, 0, len(values))
+	for value := range values {
+		sortedValues = append(sortedValues, value)
+	}
+
+	sort.Ints(sortedValues)
+
+	index := (percentile * total / 100) - 1
+	if index < 0 {
+		index = 0
+	}
+
+	position := 0
+
+	for _, value := range sortedValues {
+		position += values[value]
+		if position > index {
+			return value
+		}
+	}
+
+	return 0
+}
+
+func topValues(counts map[string]int, limit int) []domain.ValueCount {
+	values := make([]domain.ValueCount, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, domain.ValueCount{Value: value, Count: count})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+
+		return values[i].Value < values[j].Value
+	})
+
+	if limit > 0 && len(values) > limit {
+		values = values[:limit]
+	}
+
+	return values
+}
+
+func sortResponseCodes(codes map[int]domain.ResponseCode) []int {
+	type Codes struct {
+		code    int
+		summary domain.ResponseCode
+	}
+
+	sortedCodes := make([]Codes, 0, len(codes))
+	for code, summary := range codes {
+		sortedCodes = append(sortedCodes, Codes{code: code, summary: summary})
+	}
+
+	sort.Slice(sortedCodes, func(i, j int) bool {
+		if sortedCodes[i].summary.Count != sortedCodes[j].summary.Count {
+			return sortedCodes[i].summary.Count > sortedCodes[j].summary.Count
+		}
+
+		return sortedCodes[i].code < sortedCodes[j].code
+	})
+
+	sortedCodesInt := make([]int, 0, len(sortedCodes))
+	for _, code := range sortedCodes {
+		sortedCodesInt = append(sortedCodesInt, code.code)
+	}
+
+	return sortedCodesInt
+}
+
+func sortRequestedResources(resources map[string]int) []string {
+	sortedResources := topValues(resources, 0)
+
+	sortedResourcesStr := make([]string, 0, len(sortedResources))
+	for _, resource := range sortedResources {
+		sortedResourcesStr = append(sortedResourcesStr, resource.Value)
+	}
+
+	return sortedResourcesStr
+}
@@ -1,13 +1,16 @@
 package analyzer
 
 import (
+	anomaly "analyzer/internal/application/anomaly"
+	security "analyzer/internal/application/security"
 	domain "analyzer/internal/domain"
 	"fmt"
 	"path/filepath"
-	"sort"
-	"time"
+	"sync"
 )
 
+const minChunkRecords = 1000
+
 var statusCodes = map[int]string{
 	100: "Continue",
 	101: "Switching Protocols",
@@ -91,27 +94,47 @@ var statusCodes = map[int]string{
 
 type LogAnalyzer struct {
 	statusCodes map[int]string
+	detector    *anomaly.Detector
 }
 
 func NewLogAnalyzer() *LogAnalyzer {
 	return &LogAnalyzer{
 		statusCodes: statusCodes,
+		detector:    anomaly.NewDetector(),
 	}
 }
 
 func (analyzer *LogAnalyzer) Analyze(records []domain.LogRecord, config *domain.Config) (domain.LogReport, error) {
-	report := analyzer.initReport(records, config)
+	return analyzer.AnalyzeBatches(singleBatch(records), config)
+}
+
+func (analyzer *LogAnalyzer) AnalyzeBatches(batches domain.RecordBatches, config *domain.Config) (domain.LogReport, error) {
+	rules, err := analyzer.getSecurityRules(config)
+	if err != nil {
+		return *analyzer.initReport(config, 0), err
+	}
+
+	accumulators, total, err := analyzer.accumulate(batches, config, rules)
+	report := analyzer.initReport(config, total)
 
-	if len(records) == 0 {
+	if err != nil {
+		return *report, err
+	}
+
+	if total == 0 {
 		return *report, fmt.Errorf("нет записей для анализа")
 	}
 
-	analyzer.processRecords(records, report)
+	for _, accumulator := range accumulators {
+		accumulator.fill(report)
+	}
+
+	report.Anomalies = analyzer.detector.Detect(report, config.Anomaly)
 
 	return *report, nil
 }
 
-func (analyzer *LogAnalyzer) initReport(records []domain.LogRecord, config *domain.Config) *domain.LogReport {
+func (analyzer *LogAnalyzer) initReport(config *domain.Config, totalRequests int) *domain.LogReport {
 	return &domain.LogReport{
 		RequestedResources: make(map[string]int),
 		ResponseCodes:      make(map[int]domain.ResponseCode),
@@ -119,161 +142,157 @@ func (analyzer *LogAnalyzer) initReport(records []domain.LogRecord, config *doma
 		URLName:            analyzer.getURLNames(config),
 		StartDate:          config.From,
 		EndDate:            config.To,
-		TotalRequests:      len(records),
+		TotalRequests:      totalRequests,
+		Approximation:      domain.ApproxStats{Capacity: config.Approx},
 	}
 }
 
-func (analyzer *LogAnalyzer) processRecords(records []domain.LogRecord, report *domain.LogReport) {
-	var (
-		totalBodySize            int
-		bodySizes                = make([]int, 0)
-		ipRequests               = make(map[string]int)
-		totalDurationBetweenReqs time.Duration
-	)
+func (analyzer *LogAnalyzer) accumulate(batches domain.RecordBatches, config *domain.Config,
+	rules *security.Rules) ([]accumulator, int, error) {
+	partials := make([][]accumulator, 0)
+	total := 0
 
-	for ind := range records {
-		record := &records[ind]
-		totalBodySize += record.BodyBytesSent
-		bodySizes = append(bodySizes, record.BodyBytesSent)
+	err := batches(func(records []domain.LogRecord) error {
+		chunks := analyzer.splitRecords(records, config)
 
-		analyzer.updateIPRequests(ipRequests, record.RemoteAddr)
-		analyzer.updateRequestedResources(report, record.URL)
-		analyzer.updateResponseCodes(report, record.Status)
-
-		totalDurationBetweenReqs = analyzer.calculateAvgRequestTime(records, ind, totalDurationBetweenReqs)
-	}
+		for len(partials) < len(chunks) {
+			partials = append(partials, analyzer.newAccumulators(config, rules))
+		}
 
-	analyzer.setTopIPAddresses(report, ipRequests)
-	report.AvgTimeBetweenRequests = totalDurationBetweenReqs / time.Duration(report.TotalRequests-1)
-	report.AvgBodySize = totalBodySize / report.TotalRequests
-	report.Percentile95Size = calculatePercentile(bodySizes, 95)
-	report.SortedRequestedResources = sortRequestedResources(report.RequestedResources)
-	report.SortedResponseCodes = sortResponseCodes(report.ResponseCodes)
-}
+		var wg sync.WaitGroup
 
-func (analyzer *LogAnalyzer) updateIPRequests(ipRequests map[string]int, remoteAddr string) {
-	ipRequests[remoteAddr]++
-}
+		for ind, chunk := range chunks {
+			wg.Add(1)
 
-func (analyzer *LogAnalyzer) updateRequestedResources(report *domain.LogReport, url string) {
-	report.RequestedResources[url]++
-}
+			go func(accumulators []accumulator, chunk []domain.LogRecord) {
+				defer wg.Done()
 
-func (analyzer *LogAnalyzer) updateResponseCodes(report *domain.LogReport, status int) {
-	if responseCode, exists := report.ResponseCodes[status]; exists {
-		responseCode.Count++
-		report.ResponseCodes[status] = responseCode
-	} else {
-		report.ResponseCodes[status] = domain.ResponseCode{
-			Name:  analyzer.getStatusName(status),
-			Count: 1,
+				addRecords(accumulators, chunk)
+			}(partials[ind], chunk)
 		}
-	}
-}
 
-func (analyzer *LogAnalyzer) calculateAvgRequestTime(records []domain.LogRecord, index int, totalDuration time.Duration) time.Duration {
-	if index > 0 {
-		durationBetween := records[index].TimeLocal.Sub(records[index-1].TimeLocal)
-		totalDuration += durationBetween
-	}
+		wg.Wait()
 
-	return totalDuration
-}
+		total += len(records)
 
-func (analyzer *LogAnalyzer) getFileNames(config *domain.Config) []string {
-	if config.TypePath == "url" {
-		return []string{}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
-	fileNames := make([]string, 0)
+	if len(partials) == 0 {
+		return analyzer.newAccumulators(config, rules), 0, nil
+	}
 
-	matches, _ := filepath.Glob(config.Path)
+	accumulators := partials[0]
 
-	for _, file := range matches {
-		fileNames = append(fileNames, filepath.Base(file))
+	for _, partial := range partials[1:] {
+		for ind, accumulator := range accumulators {
+			accumulator.merge(partial[ind])
+		}
 	}
 
-	return fileNames
+	return accumulators, total, nil
 }
 
-func (analyzer *LogAnalyzer) getURLNames(config *domain.Config) string {
-	if config.TypePath != "url" {
-		return ""
+func (analyzer *LogAnalyzer) splitRecords(records []domain.LogRecord, config *domain.Config) [][]domain.LogRecord {
+	count := min(max(config.Workers, 1), (len(records)+minChunkRecords-1)/minChunkRecords)
+	if count <= 1 {
+		return [][]domain.LogRecord{records}
 	}
 
-	return config.Path
-}
+	chunks := make([][]domain.LogRecord, 0, count)
+	size := (len(records) + count - 1) / count
 
-func (analyzer *LogAnalyzer) setTopIPAddresses(report *domain.LogReport, ipRequests map[string]int) {
-	ips := make([]domain.IPCount, 0, len(ipRequests))
-	for ip, count := range ipRequests {
-		ips = append(ips, domain.IPCount{IP: ip, Count: count})
+	for start := 0; start < len(records); start += size {
+		chunks = append(chunks, records[start:min(start+size, len(records))])
 	}
 
-	sort.Slice(ips, func(i, j int) bool { return ips[i].Count > ips[j].Count })
+	return chunks
+}
 
-	for i := 0; i < 3 && i < len(ips); i++ {
-		report.TopIPAddresses = append(report.TopIPAddresses, ips[i])
+func singleBatch(records []domain.LogRecord) domain.RecordBatches {
+	return func(handle func(records []domain.LogRecord) error) error {
+		return handle(records)
 	}
 }
 
-func (analyzer *LogAnalyzer) getStatusName(code int) string {
-	return analyzer.statusCodes[code]
-}
+func addRecords(accumulators []accumulator, records []domain.LogRecord) {
+	for ind := range records {
+		record := &records[ind]
 
-func calculatePercentile(values []int, percentile int) int {
-	sort.Ints(values)
-	index := (percentile * len(values) / 100) - 1
+		for _, accumulator := range accumulators {
+			accumulator.add(record)
+		}
+	}
+}
 
-	if index < 0 {
-		index = 0
+func (analyzer *LogAnalyzer) newAccumulators(config *domain.Config, rules *security.Rules) []accumulator {
+	accumulators := []accumulator{
+		newGeneralAccumulator(config.Approx),
+		newResourceAccumulator(config.Approx),
+		newResponseCodeAccumulator(analyzer.statusCodes),
+		newIPAccumulator(config.Approx),
+		newGeoAccumulator(),
+		newUserAgentAccumulator(config.Approx),
+		newRefererAccumulator(config.SiteHosts, config.Approx),
+		newStatusClassAccumulator(config.Approx),
+		newTimelineAccumulator(config.BucketSize, config.Approx),
+		newTrafficAccumulator(config.BucketSize, config.Approx),
+		newSecurityAccumulator(rules, config.Approx == 0),
 	}
 
-	return values[index]
-}
+	if config.Approx == 0 {
+		accumulators = append(accumulators,
+			newSessionAccumulator(config.SessionGap),
+			newFunnelAccumulator(config.Funnel, config.FunnelWindow))
+	}
 
-func sortResponseCodes(codes map[int]domain.ResponseCode) []int {
-	type Codes struct {
-		code    int
-		summary domain.ResponseCode
+	if config.ProfileClient.IsValid() {
+		accumulators = append(accumulators,
+			newProfileAccumulator(analyzer.profileLabel(config), analyzer.statusCodes, config.BucketSize, config.Approx))
 	}
 
-	sortedCodes := make([]Codes, 0, len(codes))
-	for code, summary := range codes {
-		sortedCodes = append(sortedCodes, Codes{code: code, summary: summary})
+	return accumulators
+}
+
+func (analyzer *LogAnalyzer) profileLabel(config *domain.Config) string {
+	if config.Anonymize.Mode != "" {
+		return ""
 	}
 
-	sort.Slice(sortedCodes, func(i, j int) bool {
-		return sortedCodes[i].summary.Count > sortedCodes[j].summary.Count
-	})
+	return config.ProfileClient.String()
+}
 
-	sortedCodesInt := make([]int, 0, len(sortedCodes))
-	for _, code := range sortedCodes {
-		sortedCodesInt = append(sortedCodesInt, code.code)
+func (analyzer *LogAnalyzer) getSecurityRules(config *domain.Config) (*security.Rules, error) {
+	if config.SecurityRules == "" {
+		return security.DefaultRules(), nil
 	}
 
-	return sortedCodesInt
+	return security.LoadRules(config.SecurityRules)
 }
 
-func sortRequestedResources(resources map[string]int) []string {
-	type Resource struct {
-		resource string
-		count    int
+func (analyzer *LogAnalyzer) getFileNames(config *domain.Config) []string {
+	if config.TypePath == "url" {
+		return []string{}
 	}
 
-	sortedResources := make([]Resource, 0, len(resources))
-	for resource, count := range resources {
-		sortedResources = append(sortedResources, Resource{resource: resource, count: count})
+	fileNames := make([]string, 0)
+
+	matches, _ := filepath.Glob(config.Path)
+
+	for _, file := range matches {
+		fileNames = append(fileNames, filepath.Base(file))
 	}
 
-	sort.Slice(sortedResources, func(i, j int) bool {
-		return sortedResources[i].count > sortedResources[j].count
-	})
+	return fileNames
+}
 
-	sortedResourcesStr := make([]string, 0, len(sortedResources))
-	for _, resource := range sortedResources {
-		sortedResourcesStr = append(sortedResourcesStr, resource.resource)
+func (analyzer *LogAnalyzer) getURLNames(config *domain.Config) string {
+	if config.TypePath != "url" {
+		return ""
 	}
 
-	return sortedResourcesStr
+	return config.Path
 }
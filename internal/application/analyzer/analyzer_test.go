@@ -1,9 +1,13 @@
 package analyzer
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
 	"testing"
 	"time"
 
+	security "analyzer/internal/application/security"
 	domain "analyzer/internal/domain"
 
 	"github.com/stretchr/testify/assert"
@@ -106,3 +110,645 @@ func TestLogAnalyzer(t *testing.T) {
 			report.SortedRequestedResources, "Запрашиваемые ресурсы должны быть отсортированы")
 	})
 }
+
+func TestLogAnalyzer_UserAgents(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createTestLogRecords()
+	config := &domain.Config{}
+
+	records[0].Agent = domain.UserAgent{Browser: "Chrome", OS: "Windows", Device: "desktop"}
+	records[1].Agent = domain.UserAgent{Browser: "Chrome", OS: "Android", Device: "mobile"}
+	records[2].Agent = domain.UserAgent{Browser: "Firefox", OS: "Linux", Device: "desktop"}
+	records[3].Agent = domain.UserAgent{Bot: true, BotName: "Googlebot", Device: "bot"}
+	records[4].Agent = domain.UserAgent{Bot: true, BotName: "curl", Device: "bot"}
+
+	report, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	agents := report.UserAgents
+
+	assert.Equal(t, 2, agents.BotRequests)
+	assert.Equal(t, 3, agents.HumanRequests)
+	assert.Equal(t, []domain.ValueCount{{Value: "Chrome", Count: 2}, {Value: "Firefox", Count: 1}}, agents.TopBrowsers)
+	assert.Equal(t, []domain.ValueCount{{Value: "desktop", Count: 2}, {Value: "mobile", Count: 1}}, agents.DeviceTypes)
+	assert.Equal(t, []domain.ValueCount{{Value: "Googlebot", Count: 1}, {Value: "curl", Count: 1}}, agents.TopBots)
+}
+
+func TestLogAnalyzer_Referers(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createTestLogRecords()
+	config := &domain.Config{SiteHosts: []string{"example.com"}}
+
+	records[0].Referer = "-"
+	records[1].Referer = "https://www.example.com/catalog"
+	records[2].Referer = "https://www.google.com/search?q=data"
+	records[3].Referer = "https://www.google.com/search?q=other"
+	records[4].Referer = "https://news.ycombinator.com/item?id=1"
+
+	report, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	referers := report.Referers
+
+	assert.Equal(t, 1, referers.DirectRequests)
+	assert.Equal(t, 1, referers.InternalRequests)
+	assert.Equal(t, 3, referers.ExternalRequests)
+	assert.Equal(t, domain.ValueCount{Value: "google.com", Count: 2}, referers.TopDomains[0])
+	assert.Equal(t, []domain.ValueCount{{Value: "Google", Count: 2}}, referers.TopSearchEngines)
+	assert.Equal(t, []domain.RefererLanding{
+		{Referer: "https://news.ycombinator.com/item", URL: "/api/otherdata", Count: 1},
+		{Referer: "https://www.google.com/search", URL: "/api/data", Count: 1},
+		{Referer: "https://www.google.com/search", URL: "/api/otherdata", Count: 1},
+	}, referers.TopLandingPairs)
+}
+
+func TestLogAnalyzer_StatusClasses(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createTestLogRecords()
+	config := &domain.Config{BucketSize: 48 * time.Hour}
+
+	records[3].Status = 503
+
+	report, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	t.Run("Classes", func(t *testing.T) {
+		require.Len(t, report.StatusClasses, 3)
+		assert.Equal(t, "2xx", report.StatusClasses[0].Class)
+		assert.Equal(t, 2, report.StatusClasses[0].Count)
+		assert.Equal(t, "4xx", report.StatusClasses[1].Class)
+		assert.Equal(t, []domain.ValueCount{{Value: "192.168.1.1", Count: 1}, {Value: "192.168.1.2", Count: 1}},
+			report.StatusClasses[1].TopClients)
+		assert.Equal(t, []domain.ValueCount{{Value: "/api/otherdata", Count: 1}}, report.StatusClasses[2].TopURLs)
+	})
+
+	t.Run("ErrorRates", func(t *testing.T) {
+		assert.Equal(t, 2, report.ClientErrors)
+		assert.Equal(t, 1, report.ServerErrors)
+	})
+
+	t.Run("Timeline", func(t *testing.T) {
+		require.Len(t, report.Timeline, 3)
+		assert.Equal(t, 48*time.Hour, report.BucketSize)
+		assert.Equal(t, time.Date(2023, 10, 15, 0, 0, 0, 0, time.UTC), report.Timeline[0].Start,
+			"Интервалы выравниваются по началу дня первой записи")
+		assert.Equal(t, domain.TimeBucket{
+			Start:             time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC),
+			Requests:          2,
+			ServerErrors:      1,
+			Bytes:             1024,
+			TopClient:         "192.168.1.1",
+			TopClientRequests: 1,
+		}, report.Timeline[1])
+		assert.Equal(t, 1, report.Timeline[2].ClientErrors)
+	})
+
+	t.Run("BucketLimit", func(t *testing.T) {
+		report, err := analyzer.Analyze(records, &domain.Config{BucketSize: time.Second})
+		require.NoError(t, err)
+
+		assert.LessOrEqual(t, len(report.Timeline), maxTimelineBuckets)
+		assert.Equal(t, 39*time.Second, report.BucketSize, "Интервал укрупняется до кратного --bucket")
+		assert.Equal(t, 5, countRequests(report.Timeline))
+	})
+}
+
+func countRequests(timeline []domain.TimeBucket) int {
+	total := 0
+	for ind := range timeline {
+		total += timeline[ind].Requests
+	}
+
+	return total
+}
+
+func TestLogAnalyzer_Traffic(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createTestLogRecords()
+	config := &domain.Config{BucketSize: 24 * time.Hour}
+
+	report, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	traffic := report.Traffic
+
+	assert.Equal(t, int64(1536), traffic.TotalBytes)
+	assert.Equal(t, int64(1024), traffic.PeakBytes)
+	assert.Equal(t, time.Date(2023, 10, 17, 0, 0, 0, 0, time.UTC), traffic.PeakBucketStart)
+	assert.Equal(t, []domain.ByteCount{{Value: "/api/data", Bytes: 1536}, {Value: "/api/otherdata", Bytes: 0}}, traffic.TopURLs)
+	assert.Equal(t, []domain.ByteCount{{Value: "192.168.1.2", Bytes: 1024}, {Value: "192.168.1.1", Bytes: 512}}, traffic.TopClients)
+	assert.Equal(t, int64(512), report.Timeline[0].Bytes)
+}
+
+func TestLogAnalyzer_Security(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createTestLogRecords()
+	start := time.Date(2023, 10, 20, 10, 0, 0, 0, time.UTC)
+
+	records = append(records, domain.LogRecord{
+		RemoteAddr:      "10.0.0.66",
+		RemoteUser:      "-",
+		TimeLocal:       start,
+		Method:          "GET",
+		URL:             "/.env",
+		ProtocolVersion: "HTTP/1.1",
+		Status:          404,
+		Referer:         "-",
+		UserAgent:       "curl/8.4.0",
+	})
+
+	for i := range 25 {
+		records = append(records, domain.LogRecord{
+			RemoteAddr:      "10.0.0.66",
+			TimeLocal:       start.Add(time.Duration(i) * time.Second),
+			Method:          "POST",
+			URL:             "/login",
+			ProtocolVersion: "HTTP/1.1",
+			Status:          401,
+		})
+	}
+
+	report, err := analyzer.Analyze(records, &domain.Config{})
+	require.NoError(t, err)
+
+	stats := report.Security
+
+	assert.Equal(t, 1, stats.FlaggedRequests)
+	assert.Equal(t, []domain.ValueCount{
+		{Value: "Много ошибок доступа", Count: 26},
+		{Value: "Всплеск POST-запросов", Count: 25},
+		{Value: "Сканирование уязвимостей", Count: 1},
+	}, stats.Signals)
+
+	require.Len(t, stats.Clients, 1)
+
+	client := stats.Clients[0]
+
+	assert.Equal(t, "10.0.0.66", client.IP)
+	assert.Equal(t, 26, client.Requests)
+	require.Len(t, client.Evidence, 3)
+	assert.Equal(t, "26 из 26 запросов завершились кодами 401/403/404 (100.00%)", client.Evidence[0])
+	assert.Equal(t, "25 POST-запросов за 1m0s начиная с 20.10.2023 10:00:00", client.Evidence[1])
+	assert.Equal(t, `10.0.0.66 - - [20/Oct/2023:10:00:00 +0000] "GET /.env HTTP/1.1" 404 0 "-" "curl/8.4.0"`, client.Evidence[2])
+}
+
+func TestSecurityAccumulator_FillIsRepeatable(t *testing.T) {
+	acc := newSecurityAccumulator(security.DefaultRules(), true)
+	start := time.Date(2023, 10, 20, 10, 0, 0, 0, time.UTC)
+
+	for i := range 25 {
+		acc.add(&domain.LogRecord{RemoteAddr: "10.0.0.66", TimeLocal: start.Add(time.Duration(i) * time.Second), Method: "POST",
+			URL: "/login", Status: 401})
+	}
+
+	var first, second domain.LogReport
+
+	acc.fill(&first)
+	acc.fill(&second)
+
+	assert.Equal(t, first.Security, second.Security)
+	assert.Equal(t, []domain.ValueCount{
+		{Value: "Всплеск POST-запросов", Count: 25},
+		{Value: "Много ошибок доступа", Count: 25},
+	}, second.Security.Signals)
+}
+
+func TestLogAnalyzer_Sessions(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	start := time.Date(2023, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	visit := func(addr, user string, offset time.Duration, url string) domain.LogRecord {
+		return domain.LogRecord{RemoteAddr: addr, RemoteUser: user, TimeLocal: start.Add(offset), Method: "GET", URL: url, Status: 200}
+	}
+
+	records := []domain.LogRecord{
+		visit("10.0.0.1", "-", 0, "/"),
+		visit("10.0.0.1", "-", 2*time.Minute, "/catalog"),
+		visit("10.0.0.1", "-", 6*time.Minute, "/cart"),
+		visit("10.0.0.1", "-", 2*time.Hour, "/"),
+		visit("10.0.0.2", "alice", 0, "/login"),
+		visit("10.0.0.3", "alice", 10*time.Minute, "/catalog"),
+		visit("10.0.0.4", "-", time.Minute, "/"),
+	}
+
+	report, err := analyzer.Analyze(records, &domain.Config{SessionGap: 30 * time.Minute})
+	require.NoError(t, err)
+
+	sessions := report.Sessions
+
+	assert.Equal(t, 30*time.Minute, sessions.Gap)
+	assert.Equal(t, 4, sessions.Sessions)
+	assert.Equal(t, 3, sessions.Visitors)
+	assert.Equal(t, 2, sessions.BounceSessions)
+	assert.Equal(t, 4*time.Minute, sessions.AvgDuration)
+	assert.InDelta(t, 1.75, sessions.AvgPages, 0.001)
+	assert.Equal(t, []domain.ValueCount{{Value: "/", Count: 3}, {Value: "/login", Count: 1}}, sessions.TopEntryURLs)
+	assert.Equal(t, []domain.ValueCount{{Value: "/", Count: 2}, {Value: "/cart", Count: 1}, {Value: "/catalog", Count: 1}},
+		sessions.TopExitURLs)
+	assert.Equal(t, []domain.ValueCount{
+		{Value: "/", Count: 2}, {Value: "/ → /catalog → /cart", Count: 1}, {Value: "/login → /catalog", Count: 1},
+	}, sessions.TopPaths)
+}
+
+func TestLogAnalyzer_Funnel(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	start := time.Date(2023, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	visit := func(addr string, offset time.Duration, url string) domain.LogRecord {
+		return domain.LogRecord{RemoteAddr: addr, RemoteUser: "-", TimeLocal: start.Add(offset), Method: "GET", URL: url, Status: 200}
+	}
+
+	records := []domain.LogRecord{
+		visit("10.0.0.1", 0, "/cart"),
+		visit("10.0.0.1", 5*time.Minute, "/checkout?step=1"),
+		visit("10.0.0.1", 10*time.Minute, "/order/complete"),
+		visit("10.0.0.2", 0, "/checkout"),
+		visit("10.0.0.2", time.Minute, "/cart"),
+		visit("10.0.0.2", 2*time.Minute, "/checkout"),
+		visit("10.0.0.3", 0, "/cart"),
+		visit("10.0.0.3", 2*time.Hour, "/checkout"),
+		visit("10.0.0.4", 0, "/catalog"),
+	}
+
+	config := &domain.Config{Funnel: []string{"/cart", "/checkout", "/order/complete"}, FunnelWindow: time.Hour}
+
+	report, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.FunnelStats{
+		Window: time.Hour,
+		Steps: []domain.FunnelStep{
+			{URL: "/cart", Clients: 3},
+			{URL: "/checkout", Clients: 2},
+			{URL: "/order/complete", Clients: 1},
+		},
+	}, report.Funnel)
+}
+
+func TestLogAnalyzer_Geo(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+
+	t.Run("WithoutDatabase", func(t *testing.T) {
+		report, err := analyzer.Analyze(createTestLogRecords(), &domain.Config{})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.GeoStats{}, report.Geo)
+	})
+
+	t.Run("Resolved", func(t *testing.T) {
+		records := createTestLogRecords()
+		germany := domain.Geo{Country: "DE", CountryName: "Германия", ASN: 64500, Organization: "Example Hosting"}
+
+		records[0].Geo = germany
+		records[1].Geo = germany
+		records[2].Geo = domain.Geo{Country: "FR", CountryName: "Франция"}
+
+		report, err := analyzer.Analyze(records, &domain.Config{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, report.Geo.ResolvedRequests)
+		assert.Equal(t, []domain.ValueCount{
+			{Value: "DE Германия", Count: 2},
+			{Value: "Неизвестно", Count: 2},
+			{Value: "FR Франция", Count: 1},
+		}, report.Geo.TopCountries)
+		assert.Equal(t, []domain.ValueCount{
+			{Value: "Неизвестно", Count: 3},
+			{Value: "AS64500 Example Hosting", Count: 2},
+		}, report.Geo.TopNetworks)
+	})
+}
+
+func TestLogAnalyzer_Profile(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+
+	t.Run("Disabled", func(t *testing.T) {
+		report, err := analyzer.Analyze(createTestLogRecords(), &domain.Config{})
+		require.NoError(t, err)
+
+		assert.Nil(t, report.Profile)
+	})
+
+	t.Run("Client", func(t *testing.T) {
+		records := createTestLogRecords()
+		config := &domain.Config{ProfileClient: netip.MustParsePrefix("192.168.1.1/32"), BucketSize: 24 * time.Hour}
+
+		report, err := analyzer.Analyze([]domain.LogRecord{records[0], records[3], records[4]}, config)
+		require.NoError(t, err)
+		require.NotNil(t, report.Profile)
+
+		profile := report.Profile
+
+		assert.Equal(t, "192.168.1.1/32", profile.Client)
+		assert.Equal(t, records[0].TimeLocal, profile.FirstSeen)
+		assert.Equal(t, records[4].TimeLocal, profile.LastSeen)
+		assert.Equal(t, 3, profile.Requests)
+		assert.Equal(t, int64(512), profile.Bytes)
+		assert.Equal(t, []domain.ValueCount{{Value: "200 OK", Count: 2}, {Value: "404 Not Found", Count: 1}}, profile.Statuses)
+		assert.Equal(t, []domain.ValueCount{{Value: "GET", Count: 2}, {Value: "DELETE", Count: 1}}, profile.Methods)
+		assert.Equal(t, []domain.ValueCount{{Value: "/api/otherdata", Count: 2}, {Value: "/api/data", Count: 1}}, profile.URLs)
+
+		assert.Equal(t, 24*time.Hour, profile.BucketSize)
+		require.Len(t, profile.Timeline, 5)
+		assert.Equal(t, time.Date(2023, 10, 15, 0, 0, 0, 0, time.UTC), profile.Timeline[0].Start)
+		assert.Equal(t, []int{1, 0, 0, 1, 1}, []int{profile.Timeline[0].Requests, profile.Timeline[1].Requests,
+			profile.Timeline[2].Requests, profile.Timeline[3].Requests, profile.Timeline[4].Requests})
+		assert.Equal(t, 1, profile.Timeline[4].ClientErrors)
+	})
+
+	t.Run("Anonymized", func(t *testing.T) {
+		config := &domain.Config{
+			ProfileClient: netip.MustParsePrefix("192.168.1.1/32"),
+			Anonymize:     domain.AnonymizeOptions{Mode: domain.AnonymizeTruncate},
+		}
+
+		report, err := analyzer.Analyze(createTestLogRecords()[:1], config)
+		require.NoError(t, err)
+
+		assert.Empty(t, report.Profile.Client)
+	})
+}
+
+func TestLogAnalyzer_Approx(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	start := time.Date(2023, 10, 15, 10, 0, 0, 0, time.UTC)
+	records := make([]domain.LogRecord, 0)
+
+	for i := range 2000 {
+		record := domain.LogRecord{
+			RemoteAddr: fmt.Sprintf("10.0.%d.%d", i/250, i%250),
+			TimeLocal:  start.Add(time.Duration(i) * time.Second),
+			Method:     "GET",
+			URL:        fmt.Sprintf("/search?nocache=%d", i),
+			Status:     200,
+			UserAgent:  fmt.Sprintf("scanner/%d", i),
+		}
+
+		if i%4 == 0 {
+			record.RemoteAddr = "203.0.113.7"
+			record.URL = "/"
+			record.UserAgent = "Mozilla/5.0"
+		}
+
+		records = append(records, record)
+	}
+
+	exact, err := analyzer.Analyze(records, &domain.Config{})
+	require.NoError(t, err)
+
+	approx, err := analyzer.Analyze(records, &domain.Config{Approx: 50})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.ApproxStats{}, exact.Approximation)
+	assert.Len(t, exact.RequestedResources, 1501)
+	assert.Len(t, approx.RequestedResources, 50)
+
+	assert.Equal(t, 50, approx.Approximation.Capacity)
+	assert.LessOrEqual(t, approx.Approximation.IPError, len(records)/50)
+	assert.LessOrEqual(t, approx.Approximation.ResourceError, len(records)/50)
+	assert.LessOrEqual(t, approx.Approximation.UserAgentError, len(records)/50)
+
+	assert.Equal(t, exact.TopIPAddresses[0].IP, approx.TopIPAddresses[0].IP)
+	assert.GreaterOrEqual(t, approx.TopIPAddresses[0].Count, 500)
+	assert.Equal(t, "/", approx.SortedRequestedResources[0])
+	assert.Equal(t, "Mozilla/5.0", approx.UserAgents.TopAgents[0].Value)
+
+	parallel, err := analyzer.Analyze(records, &domain.Config{Approx: 50, Workers: 4})
+	require.NoError(t, err)
+
+	assert.Len(t, parallel.RequestedResources, 50)
+	assert.LessOrEqual(t, parallel.Approximation.ResourceError, len(records)/50)
+	assert.Equal(t, exact.TopIPAddresses[0].IP, parallel.TopIPAddresses[0].IP)
+	assert.Equal(t, "/", parallel.SortedRequestedResources[0])
+}
+
+func TestLogAnalyzer_ApproxBoundsState(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createWorkloadRecords()
+
+	for ind := range records {
+		records[ind].URL = fmt.Sprintf("/item/%d", ind)
+		records[ind].RemoteAddr = fmt.Sprintf("10.%d.%d.1", ind/250, ind%250)
+		records[ind].Referer = fmt.Sprintf("https://example.org/%d", ind)
+		records[ind].BodyBytesSent = 1000 + ind
+	}
+
+	config := &domain.Config{Approx: 20, Workers: 2, BucketSize: 24 * time.Hour, ProfileClient: netip.MustParsePrefix("10.0.0.0/8")}
+
+	accumulators, total, err := analyzer.accumulate(singleBatch(records), config, security.DefaultRules())
+	require.NoError(t, err)
+	assert.Equal(t, len(records), total)
+
+	bounded := func(values counter) {
+		assert.LessOrEqual(t, len(values.counts()), config.Approx)
+	}
+
+	for _, accumulator := range accumulators {
+		switch acc := accumulator.(type) {
+		case *generalAccumulator:
+			assert.LessOrEqual(t, len(acc.bodySizes), 1<<(sizePrecisionBits+1))
+		case *trafficAccumulator:
+			bounded(acc.urls)
+			bounded(acc.clients)
+		case *statusClassAccumulator:
+			for i := range statusClassCount {
+				bounded(acc.urls[i])
+				bounded(acc.clients[i])
+			}
+		case *timelineAccumulator:
+			for _, values := range acc.clients.counters {
+				bounded(values)
+			}
+		case *refererAccumulator:
+			bounded(acc.domains)
+			bounded(acc.pairs)
+		case *profileAccumulator:
+			bounded(acc.addresses)
+			bounded(acc.urls)
+		case *securityAccumulator:
+			assert.Nil(t, acc.clients)
+		case *sessionAccumulator, *funnelAccumulator:
+			assert.Fail(t, "Сессии и воронка не должны строиться в режиме --approx")
+		}
+	}
+
+	report, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 1000+len(records)*95/100, report.Percentile95Size, float64(report.Percentile95Size)/(1<<(sizePrecisionBits-1)))
+	assert.Zero(t, report.Sessions.Sessions)
+	assert.Empty(t, report.Security.Clients)
+	assert.NotEmpty(t, report.Security.Signals)
+}
+
+func createWorkloadRecords() []domain.LogRecord {
+	start := time.Date(2023, 10, 15, 10, 0, 0, 0, time.UTC)
+	urls := []string{"/", "/cart", "/checkout", "/login", "/.env", "/search?q=<script>"}
+	records := make([]domain.LogRecord, 0)
+
+	for i := range 5000 {
+		records = append(records, domain.LogRecord{
+			RemoteAddr:    fmt.Sprintf("10.0.0.%d", i%37),
+			RemoteUser:    "-",
+			TimeLocal:     start.Add(time.Duration(i*7) * time.Second),
+			Method:        []string{"GET", "POST", "GET", "TRACE"}[i%4],
+			URL:           urls[i%len(urls)],
+			Status:        []int{200, 200, 401, 404, 500}[i%5],
+			BodyBytesSent: i % 3000,
+			Referer:       []string{"-", "https://www.google.com/search?q=shop", "https://example.org/a b"}[i%3],
+			UserAgent:     fmt.Sprintf("agent/%d", i%11),
+		})
+	}
+
+	return records
+}
+
+func createWorkloadConfig(workers int) *domain.Config {
+	return &domain.Config{
+		Workers:       workers,
+		BucketSize:    domain.DefaultBucketSize,
+		SessionGap:    domain.DefaultSessionGap,
+		Funnel:        []string{"/cart", "/checkout"},
+		FunnelWindow:  domain.DefaultFunnelWindow,
+		ProfileClient: netip.MustParsePrefix("10.0.0.0/29"),
+	}
+}
+
+func TestLogAnalyzer_Workers(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createWorkloadRecords()
+
+	sequential, err := analyzer.Analyze(records, createWorkloadConfig(1))
+	require.NoError(t, err)
+
+	parallel, err := analyzer.Analyze(records, createWorkloadConfig(4))
+	require.NoError(t, err)
+
+	assert.Equal(t, sequential, parallel)
+	assert.NotEmpty(t, parallel.Security.Clients)
+	assert.Len(t, analyzer.splitRecords(records, createWorkloadConfig(4)), 4)
+	assert.Len(t, analyzer.splitRecords(records, &domain.Config{Workers: 4, Approx: 100}), 4)
+}
+
+func TestLogAnalyzer_AnalyzeBatches(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createWorkloadRecords()
+
+	whole, err := analyzer.Analyze(records, createWorkloadConfig(1))
+	require.NoError(t, err)
+
+	batches := func(handle func(records []domain.LogRecord) error) error {
+		for start := 0; start < len(records); start += 1500 {
+			if err := handle(records[start:min(start+1500, len(records))]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	batched, err := analyzer.AnalyzeBatches(batches, createWorkloadConfig(4))
+	require.NoError(t, err)
+
+	assert.Equal(t, whole, batched)
+
+	_, err = analyzer.AnalyzeBatches(func(func(records []domain.LogRecord) error) error {
+		return fmt.Errorf("обрыв чтения")
+	}, createWorkloadConfig(1))
+	assert.ErrorContains(t, err, "обрыв чтения")
+}
+
+func TestLogAnalyzer_MergeStates(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createWorkloadRecords()
+	config := createWorkloadConfig(1)
+
+	expected, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	states := make([]domain.AnalyzerState, 0)
+
+	for _, part := range [][]domain.LogRecord{records[:1200], records[1200:1201], records[1201:]} {
+		state, err := analyzer.State(part, config)
+		require.NoError(t, err)
+
+		data, err := json.Marshal(state)
+		require.NoError(t, err)
+
+		var loaded domain.AnalyzerState
+
+		require.NoError(t, json.Unmarshal(data, &loaded))
+
+		states = append(states, loaded)
+	}
+
+	merged, err := analyzer.MergeStates(states, &domain.Config{})
+	require.NoError(t, err)
+
+	report, err := analyzer.AnalyzeState(&merged, &domain.Config{})
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, report)
+
+	t.Run("DifferentSettings", func(t *testing.T) {
+		other, err := analyzer.State(records[:10], &domain.Config{BucketSize: time.Minute})
+		require.NoError(t, err)
+
+		_, err = analyzer.MergeStates([]domain.AnalyzerState{states[0], other}, &domain.Config{})
+		assert.Error(t, err)
+	})
+
+	t.Run("DifferentPreprocessing", func(t *testing.T) {
+		for _, config := range []*domain.Config{
+			{URLOptions: domain.URLOptions{CollapseIDs: true}},
+			{URLOptions: domain.URLOptions{StripQuery: []string{domain.StripAllQuery}}},
+			{ClientIP: domain.ClientIPOptions{Mode: domain.ClientIPForwarded,
+				TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}},
+			{UserAgentRules: "ua-rules.txt"},
+		} {
+			other, err := analyzer.State(records[:10], config)
+			require.NoError(t, err)
+
+			_, err = analyzer.MergeStates([]domain.AnalyzerState{states[0], other}, &domain.Config{})
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		state := states[0]
+		state.Version = domain.StateVersion + 1
+
+		_, err := analyzer.AnalyzeState(&state, &domain.Config{})
+		assert.Error(t, err)
+	})
+}
+
+func TestLogAnalyzer_Rollups(t *testing.T) {
+	analyzer := NewLogAnalyzer()
+	records := createWorkloadRecords()
+	config := &domain.Config{BucketSize: domain.DefaultBucketSize}
+
+	expected, err := analyzer.Analyze(records, config)
+	require.NoError(t, err)
+
+	rollups := analyzer.Rollups(records)
+	require.Len(t, rollups, (5000*7+59)/60)
+
+	report, err := analyzer.AnalyzeRollups(rollups, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.TotalRequests, report.TotalRequests)
+	assert.Equal(t, expected.AvgBodySize, report.AvgBodySize)
+	assert.Equal(t, expected.Percentile95Size, report.Percentile95Size)
+	assert.Equal(t, expected.AvgTimeBetweenRequests, report.AvgTimeBetweenRequests)
+	assert.Equal(t, expected.RequestedResources, report.RequestedResources)
+	assert.Equal(t, expected.ResponseCodes, report.ResponseCodes)
+	assert.Equal(t, expected.TopIPAddresses, report.TopIPAddresses)
+	assert.Equal(t, expected.Timeline, report.Timeline)
+	assert.Equal(t, expected.Traffic, report.Traffic)
+	assert.Equal(t, expected.Anomalies, report.Anomalies)
+	assert.Equal(t, expected.StatusClasses[0].Count, report.StatusClasses[0].Count)
+
+	_, err = analyzer.AnalyzeRollups(nil, config)
+	assert.Error(t, err)
+}
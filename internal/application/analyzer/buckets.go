@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"time"
+)
+
+const (
+	day                = 24 * time.Hour
+	maxTimelineBuckets = 10_000
+)
+
+type bucketLayout struct {
+	size   time.Duration
+	origin int64
+	first  int64
+	last   int64
+}
+
+func slotSize(bucketSize time.Duration) time.Duration {
+	size, rest := day, bucketSize
+
+	for rest != 0 {
+		size, rest = rest, size%rest
+	}
+
+	return size
+}
+
+func slotStart(moment time.Time, bucketSize time.Duration) int64 {
+	return moment.UTC().Truncate(slotSize(bucketSize)).Unix()
+}
+
+func newBucketLayout[V any](slots map[int64]V, bucketSize time.Duration) (bucketLayout, bool) {
+	if len(slots) == 0 {
+		return bucketLayout{size: bucketSize}, false
+	}
+
+	first, last := int64(0), int64(0)
+	found := false
+
+	for slot := range slots {
+		if !found || slot < first {
+			first = slot
+		}
+
+		if !found || slot > last {
+			last = slot
+		}
+
+		found = true
+	}
+
+	layout := bucketLayout{
+		size:   bucketSize,
+		origin: time.Unix(first, 0).UTC().Truncate(day).Unix(),
+	}
+
+	if count := (last-layout.origin)/layout.step() + 1; count > maxTimelineBuckets {
+		layout.size *= time.Duration((count + maxTimelineBuckets - 1) / maxTimelineBuckets)
+	}
+
+	layout.first = layout.start(first)
+	layout.last = layout.start(last)
+
+	return layout, true
+}
+
+func (layout bucketLayout) step() int64 {
+	return int64(layout.size / time.Second)
+}
+
+func (layout bucketLayout) start(slot int64) int64 {
+	return layout.origin + (slot-layout.origin)/layout.step()*layout.step()
+}
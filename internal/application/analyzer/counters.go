@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"analyzer/pkg/topk"
+	"encoding/json"
+)
+
+type counter interface {
+	add(key string, count int)
+	merge(other counter)
+	counts() map[string]int
+	maxError() int
+}
+
+func newCounter(capacity int) counter {
+	if capacity <= 0 {
+		return make(exactCounter)
+	}
+
+	return &approxCounter{summary: topk.NewSpaceSaving(capacity)}
+}
+
+type exactCounter map[string]int
+
+func (values exactCounter) add(key string, count int) {
+	values[key] += count
+}
+
+func (values exactCounter) merge(other counter) {
+	mergeCounts(values, other.(exactCounter))
+}
+
+func (values exactCounter) counts() map[string]int {
+	return values
+}
+
+func (values exactCounter) maxError() int {
+	return 0
+}
+
+type approxCounter struct {
+	summary *topk.SpaceSaving
+}
+
+func (values *approxCounter) add(key string, count int) {
+	values.summary.Add(key, count)
+}
+
+func (values *approxCounter) merge(other counter) {
+	values.summary.Merge(other.(*approxCounter).summary)
+}
+
+func (values *approxCounter) counts() map[string]int {
+	return values.summary.Counts()
+}
+
+func (values *approxCounter) maxError() int {
+	return values.summary.MaxError()
+}
+
+type counterState struct {
+	counter counter
+}
+
+func (state *counterState) MarshalJSON() ([]byte, error) {
+	if values, ok := state.counter.(*approxCounter); ok {
+		return json.Marshal(values.summary)
+	}
+
+	return json.Marshal(state.counter)
+}
+
+func (state *counterState) UnmarshalJSON(data []byte) error {
+	switch values := state.counter.(type) {
+	case *approxCounter:
+		return json.Unmarshal(data, values.summary)
+	case exactCounter:
+		var counts map[string]int
+
+		if err := json.Unmarshal(data, &counts); err != nil {
+			return err
+		}
+
+		mergeCounts(values, counts)
+	}
+
+	return nil
+}
+
+type counterGroup[K comparable] struct {
+	capacity int
+	counters map[K]counter
+}
+
+func newCounterGroup[K comparable](capacity int) *counterGroup[K] {
+	return &counterGroup[K]{capacity: capacity, counters: make(map[K]counter)}
+}
+
+func (group *counterGroup[K]) get(key K) counter {
+	values, exists := group.counters[key]
+	if !exists {
+		values = newCounter(group.capacity)
+		group.counters[key] = values
+	}
+
+	return values
+}
+
+func (group *counterGroup[K]) merge(other *counterGroup[K]) {
+	for key, values := range other.counters {
+		group.get(key).merge(values)
+	}
+}
+
+func (group *counterGroup[K]) MarshalJSON() ([]byte, error) {
+	states := make(map[K]*counterState, len(group.counters))
+	for key, values := range group.counters {
+		states[key] = &counterState{values}
+	}
+
+	return json.Marshal(states)
+}
+
+func (group *counterGroup[K]) UnmarshalJSON(data []byte) error {
+	var states map[K]json.RawMessage
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return err
+	}
+
+	for key, state := range states {
+		if err := json.Unmarshal(state, &counterState{group.get(key)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mergeCounts[K comparable](target, source map[K]int) {
+	for key, count := range source {
+		target[key] += count
+	}
+}
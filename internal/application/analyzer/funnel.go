@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"strings"
+	"time"
+)
+
+type funnelAccumulator struct {
+	steps  []string
+	window time.Duration
+	visits clientVisits
+}
+
+func newFunnelAccumulator(steps []string, window time.Duration) *funnelAccumulator {
+	if window <= 0 {
+		window = domain.DefaultFunnelWindow
+	}
+
+	return &funnelAccumulator{
+		steps:  steps,
+		window: window,
+		visits: make(clientVisits),
+	}
+}
+
+func (acc *funnelAccumulator) add(record *domain.LogRecord) {
+	if acc.stepIndex(record.URL) < 0 {
+		return
+	}
+
+	acc.visits.add(record)
+}
+
+func (acc *funnelAccumulator) fields() []any {
+	return []any{&acc.visits}
+}
+
+func (acc *funnelAccumulator) merge(other accumulator) {
+	acc.visits.merge(other.(*funnelAccumulator).visits)
+}
+
+func (acc *funnelAccumulator) fill(report *domain.LogReport) {
+	if len(acc.steps) == 0 {
+		return
+	}
+
+	reached := make([]int, len(acc.steps))
+
+	for key := range acc.visits {
+		depth := acc.depth(acc.visits.sorted(key))
+
+		for i := range depth {
+			reached[i]++
+		}
+	}
+
+	report.Funnel = domain.FunnelStats{
+		Window: acc.window,
+		Steps:  make([]domain.FunnelStep, 0, len(acc.steps)),
+	}
+
+	for i, step := range acc.steps {
+		report.Funnel.Steps = append(report.Funnel.Steps, domain.FunnelStep{URL: step, Clients: reached[i]})
+	}
+}
+
+func (acc *funnelAccumulator) depth(visits []visit) int {
+	best := 0
+
+	for start, first := range visits {
+		if acc.stepIndex(first.url) != 0 {
+			continue
+		}
+
+		depth := 1
+
+		for _, next := range visits[start+1:] {
+			if depth == len(acc.steps) || next.time.Sub(first.time) > acc.window {
+				break
+			}
+
+			if acc.stepIndex(next.url) == depth {
+				depth++
+			}
+		}
+
+		best = max(best, depth)
+
+		if best == len(acc.steps) {
+			break
+		}
+	}
+
+	return best
+}
+
+func (acc *funnelAccumulator) stepIndex(url string) int {
+	path, _, _ := strings.Cut(url, "?")
+
+	for i, step := range acc.steps {
+		if url == step || path == step {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"fmt"
+	"strings"
+)
+
+type geoAccumulator struct {
+	resolved  int
+	countries map[string]int
+	networks  map[string]int
+}
+
+func newGeoAccumulator() *geoAccumulator {
+	return &geoAccumulator{
+		countries: make(map[string]int),
+		networks:  make(map[string]int),
+	}
+}
+
+func (acc *geoAccumulator) add(record *domain.LogRecord) {
+	geo := &record.Geo
+
+	if geo.Country != "" || geo.ASN != 0 {
+		acc.resolved++
+	}
+
+	acc.countries[countryName(geo)]++
+	acc.networks[networkName(geo)]++
+}
+
+func (acc *geoAccumulator) fields() []any {
+	return []any{&acc.resolved, &acc.countries, &acc.networks}
+}
+
+func (acc *geoAccumulator) merge(other accumulator) {
+	source := other.(*geoAccumulator)
+
+	acc.resolved += source.resolved
+	mergeCounts(acc.countries, source.countries)
+	mergeCounts(acc.networks, source.networks)
+}
+
+func (acc *geoAccumulator) fill(report *domain.LogReport) {
+	if acc.resolved == 0 {
+		return
+	}
+
+	report.Geo = domain.GeoStats{
+		ResolvedRequests: acc.resolved,
+		TopCountries:     topValues(acc.countries, topLimit),
+		TopNetworks:      topValues(acc.networks, topLimit),
+	}
+}
+
+func countryName(geo *domain.Geo) string {
+	if geo.Country == "" {
+		return unknownValue
+	}
+
+	return strings.TrimSpace(geo.Country + " " + geo.CountryName)
+}
+
+func networkName(geo *domain.Geo) string {
+	if geo.ASN == 0 {
+		return unknownValue
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("AS%d %s", geo.ASN, geo.Organization))
+}
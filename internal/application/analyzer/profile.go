@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const profileLimit = 10
+
+type profileAccumulator struct {
+	client      string
+	statusNames map[int]string
+	requests    int
+	bytes       int64
+	firstSeen   time.Time
+	lastSeen    time.Time
+	addresses   counter
+	statuses    map[string]int
+	methods     counter
+	urls        counter
+	userAgents  counter
+	activity    *timelineAccumulator
+}
+
+func newProfileAccumulator(client string, statusNames map[int]string, bucketSize time.Duration,
+	capacity int) *profileAccumulator {
+	return &profileAccumulator{
+		client:      client,
+		statusNames: statusNames,
+		addresses:   newCounter(capacity),
+		statuses:    make(map[string]int),
+		methods:     newCounter(capacity),
+		urls:        newCounter(capacity),
+		userAgents:  newCounter(capacity),
+		activity:    newTimelineAccumulator(bucketSize, capacity),
+	}
+}
+
+func (acc *profileAccumulator) add(record *domain.LogRecord) {
+	if acc.requests == 0 || record.TimeLocal.Before(acc.firstSeen) {
+		acc.firstSeen = record.TimeLocal
+	}
+
+	if record.TimeLocal.After(acc.lastSeen) {
+		acc.lastSeen = record.TimeLocal
+	}
+
+	acc.requests++
+	acc.bytes += int64(record.BodyBytesSent)
+	acc.addresses.add(record.RemoteAddr, 1)
+	acc.statuses[acc.statusName(record.Status)]++
+	acc.methods.add(record.Method, 1)
+	acc.urls.add(record.URL, 1)
+	acc.userAgents.add(record.UserAgent, 1)
+	acc.activity.add(record)
+}
+
+func (acc *profileAccumulator) fields() []any {
+	return []any{&acc.requests, &acc.bytes, &acc.firstSeen, &acc.lastSeen,
+		&counterState{acc.addresses}, &acc.statuses, &counterState{acc.methods}, &counterState{acc.urls},
+		&counterState{acc.userAgents}, &acc.activity.buckets, acc.activity.clients}
+}
+
+func (acc *profileAccumulator) merge(other accumulator) {
+	source := other.(*profileAccumulator)
+
+	if source.requests == 0 {
+		return
+	}
+
+	if acc.requests == 0 || source.firstSeen.Before(acc.firstSeen) {
+		acc.firstSeen = source.firstSeen
+	}
+
+	if source.lastSeen.After(acc.lastSeen) {
+		acc.lastSeen = source.lastSeen
+	}
+
+	acc.requests += source.requests
+	acc.bytes += source.bytes
+	acc.addresses.merge(source.addresses)
+	mergeCounts(acc.statuses, source.statuses)
+	acc.methods.merge(source.methods)
+	acc.urls.merge(source.urls)
+	acc.userAgents.merge(source.userAgents)
+	acc.activity.merge(source.activity)
+}
+
+func (acc *profileAccumulator) fill(report *domain.LogReport) {
+	if acc.requests == 0 {
+		return
+	}
+
+	report.Profile = &domain.ClientProfile{
+		Client:     acc.client,
+		FirstSeen:  acc.firstSeen,
+		LastSeen:   acc.lastSeen,
+		Requests:   acc.requests,
+		Bytes:      acc.bytes,
+		Addresses:  topValues(acc.addresses.counts(), profileLimit),
+		Statuses:   topValues(acc.statuses, 0),
+		Methods:    topValues(acc.methods.counts(), 0),
+		URLs:       topValues(acc.urls.counts(), profileLimit),
+		UserAgents: topValues(acc.userAgents.counts(), profileLimit),
+	}
+
+	report.Profile.BucketSize, report.Profile.Timeline = acc.activity.timeline()
+}
+
+func (acc *profileAccumulator) statusName(status int) string {
+	if name, exists := acc.statusNames[status]; exists {
+		return fmt.Sprintf("%d %s", status, name)
+	}
+
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var searchEngines = map[string]string{
+	"google":     "Google",
+	"bing":       "Bing",
+	"yandex":     "Yandex",
+	"duckduckgo": "DuckDuckGo",
+	"yahoo":      "Yahoo",
+	"baidu":      "Baidu",
+	"ecosia":     "Ecosia",
+	"brave":      "Brave Search",
+	"qwant":      "Qwant",
+	"naver":      "Naver",
+	"seznam":     "Seznam",
+}
+
+type refererPair struct {
+	referer string
+	url     string
+}
+
+func (pair refererPair) MarshalText() ([]byte, error) {
+	return []byte(strconv.Quote(pair.referer) + " " + pair.url), nil
+}
+
+func (pair *refererPair) UnmarshalText(text []byte) error {
+	referer, err := strconv.QuotedPrefix(string(text))
+	if err != nil {
+		return err
+	}
+
+	pair.url = strings.TrimPrefix(string(text[len(referer):]), " ")
+	pair.referer, err = strconv.Unquote(referer)
+
+	return err
+}
+
+type refererAccumulator struct {
+	siteHosts []string
+	direct    int
+	internal  int
+	external  int
+	domains   counter
+	engines   map[string]int
+	pairs     counter
+}
+
+func newRefererAccumulator(siteHosts []string, capacity int) *refererAccumulator {
+	return &refererAccumulator{
+		siteHosts: siteHosts,
+		domains:   newCounter(capacity),
+		engines:   make(map[string]int),
+		pairs:     newCounter(capacity),
+	}
+}
+
+func (acc *refererAccumulator) add(record *domain.LogRecord) {
+	referer := strings.TrimSpace(record.Referer)
+	if referer == "" || referer == "-" {
+		acc.direct++
+		return
+	}
+
+	host := refererHost(referer)
+	if host == "" {
+		acc.external++
+		return
+	}
+
+	acc.domains.add(host, 1)
+
+	if acc.isInternal(host) {
+		acc.internal++
+		return
+	}
+
+	acc.external++
+
+	if engine := searchEngine(host); engine != "" {
+		acc.engines[engine]++
+	}
+
+	pair, _ := refererPair{referer: stripQuery(referer), url: record.URL}.MarshalText()
+	acc.pairs.add(string(pair), 1)
+}
+
+func (acc *refererAccumulator) fields() []any {
+	return []any{&acc.direct, &acc.internal, &acc.external, &counterState{acc.domains}, &acc.engines, &counterState{acc.pairs}}
+}
+
+func (acc *refererAccumulator) merge(other accumulator) {
+	source := other.(*refererAccumulator)
+
+	acc.direct += source.direct
+	acc.internal += source.internal
+	acc.external += source.external
+	acc.domains.merge(source.domains)
+	mergeCounts(acc.engines, source.engines)
+	acc.pairs.merge(source.pairs)
+}
+
+func (acc *refererAccumulator) fill(report *domain.LogReport) {
+	report.Referers = domain.RefererStats{
+		DirectRequests:   acc.direct,
+		InternalRequests: acc.internal,
+		ExternalRequests: acc.external,
+		TopDomains:       topValues(acc.domains.counts(), topLimit),
+		TopSearchEngines: topValues(acc.engines, topLimit),
+		TopLandingPairs:  topPairs(acc.pairs.counts(), topLimit),
+	}
+}
+
+func (acc *refererAccumulator) isInternal(host string) bool {
+	for _, siteHost := range acc.siteHosts {
+		if host == siteHost || strings.HasSuffix(host, "."+siteHost) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func refererHost(referer string) string {
+	parsedURL, err := url.Parse(referer)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), "www.")
+}
+
+func searchEngine(host string) string {
+	for _, label := range strings.Split(host, ".") {
+		if engine, exists := searchEngines[label]; exists {
+			return engine
+		}
+	}
+
+	return ""
+}
+
+func stripQuery(rawURL string) string {
+	path, _, _ := strings.Cut(rawURL, "?")
+	return path
+}
+
+func topPairs(pairs map[string]int, limit int) []domain.RefererLanding {
+	landings := make([]domain.RefererLanding, 0, len(pairs))
+
+	for key, count := range pairs {
+		var pair refererPair
+
+		if err := pair.UnmarshalText([]byte(key)); err != nil {
+			continue
+		}
+
+		landings = append(landings, domain.RefererLanding{Referer: pair.referer, URL: pair.url, Count: count})
+	}
+
+	sort.Slice(landings, func(i, j int) bool {
+		if landings[i].Count != landings[j].Count {
+			return landings[i].Count > landings[j].Count
+		}
+
+		if landings[i].Referer != landings[j].Referer {
+			return landings[i].Referer < landings[j].Referer
+		}
+
+		return landings[i].URL < landings[j].URL
+	})
+
+	if len(landings) > limit {
+		landings = landings[:limit]
+	}
+
+	return landings
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const rollupSize = time.Minute
+
+func (analyzer *LogAnalyzer) Rollups(records []domain.LogRecord) []domain.Rollup {
+	rollups := make(map[int64]*domain.Rollup)
+
+	for ind := range records {
+		record := &records[ind]
+		start := record.TimeLocal.UTC().Truncate(rollupSize)
+
+		rollup, exists := rollups[start.Unix()]
+		if !exists {
+			created := domain.NewRollup(start)
+			rollup = &created
+			rollups[start.Unix()] = rollup
+		}
+
+		rollup.Add(record)
+	}
+
+	result := make([]domain.Rollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		result = append(result, *rollup)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+
+	return result
+}
+
+func (analyzer *LogAnalyzer) AnalyzeRollups(rollups []domain.Rollup, config *domain.Config) (domain.LogReport, error) {
+	general := newGeneralAccumulator(0)
+	resources := make(exactCounter)
+	codes := newResponseCodeAccumulator(analyzer.statusCodes)
+	clients := make(exactCounter)
+	classes := newStatusClassAccumulator(0)
+	timeline := newTimelineAccumulator(config.BucketSize, 0)
+	traffic := newTrafficAccumulator(config.BucketSize, 0)
+
+	for ind := range rollups {
+		rollup := &rollups[ind]
+
+		if general.count == 0 || rollup.FirstRequest.Before(general.firstTime) {
+			general.firstTime = rollup.FirstRequest
+		}
+
+		if rollup.LastRequest.After(general.lastTime) {
+			general.lastTime = rollup.LastRequest
+		}
+
+		general.count += rollup.Requests
+		general.totalBodySize += int(rollup.Bytes)
+		mergeCounts(general.bodySizes, rollup.BodySizes)
+
+		start := slotStart(rollup.Start, timeline.bucketSize)
+
+		bucket, exists := timeline.buckets[start]
+		if !exists {
+			bucket = &domain.TimeBucket{Start: time.Unix(start, 0).UTC()}
+			timeline.buckets[start] = bucket
+		}
+
+		bucket.Requests += rollup.Requests
+		bucket.Bytes += rollup.Bytes
+		traffic.totalBytes += rollup.Bytes
+		traffic.buckets[start] += rollup.Bytes
+
+		for status, count := range rollup.Statuses {
+			responseCode := codes.codes[status]
+			responseCode.Name = analyzer.statusCodes[status]
+			responseCode.Count += count
+			codes.codes[status] = responseCode
+
+			if class, ok := statusClassIndex(status); ok {
+				classes.counts[class] += count
+			}
+
+			if isClientError(status) {
+				bucket.ClientErrors += count
+			}
+
+			if isServerError(status) {
+				bucket.ServerErrors += count
+			}
+		}
+
+		for url, usage := range rollup.URLs {
+			resources[url] += usage.Requests
+			traffic.urls.add(url, int(usage.Bytes))
+		}
+
+		for client, usage := range rollup.Clients {
+			clients[client] += usage.Requests
+			timeline.clients.get(start).add(client, usage.Requests)
+			traffic.clients.add(client, int(usage.Bytes))
+		}
+	}
+
+	report := analyzer.initReport(config, general.count)
+	report.FileNames = []string{}
+	report.URLName = config.Store
+
+	if general.count == 0 {
+		return *report, fmt.Errorf("нет данных в хранилище за указанный период")
+	}
+
+	accumulators := []accumulator{
+		general,
+		&resourceAccumulator{resources: resources},
+		codes,
+		&ipAccumulator{ipRequests: clients},
+		classes,
+		timeline,
+		traffic,
+	}
+
+	for _, accumulator := range accumulators {
+		accumulator.fill(report)
+	}
+
+	report.Anomalies = analyzer.detector.Detect(report, config.Anomaly)
+
+	return *report, nil
+}
@@ -0,0 +1,267 @@
+package analyzer
+
+import (
+	security "analyzer/internal/application/security"
+	domain "analyzer/internal/domain"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	securityClientLimit = 20
+	evidenceLimit       = 5
+)
+
+type securityClient struct {
+	requests     int
+	authFailures int
+	posts        []time.Time
+	signals      map[string]int
+	evidence     []string
+}
+
+type securityClientState struct {
+	Requests     int            `json:"requests"`
+	AuthFailures int            `json:"auth_failures"`
+	Posts        []time.Time    `json:"posts"`
+	Signals      map[string]int `json:"signals"`
+	Evidence     []string       `json:"evidence"`
+}
+
+func (client *securityClient) MarshalJSON() ([]byte, error) {
+	return json.Marshal(securityClientState{
+		Requests:     client.requests,
+		AuthFailures: client.authFailures,
+		Posts:        client.posts,
+		Signals:      client.signals,
+		Evidence:     client.evidence,
+	})
+}
+
+func (client *securityClient) UnmarshalJSON(data []byte) error {
+	state := securityClientState{Signals: make(map[string]int)}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	*client = securityClient{
+		requests:     state.Requests,
+		authFailures: state.AuthFailures,
+		posts:        state.Posts,
+		signals:      state.Signals,
+		evidence:     state.Evidence,
+	}
+
+	return nil
+}
+
+type securityAccumulator struct {
+	rules   *security.Rules
+	flagged int
+	signals map[string]int
+	clients map[string]*securityClient
+}
+
+func newSecurityAccumulator(rules *security.Rules, trackClients bool) *securityAccumulator {
+	acc := &securityAccumulator{
+		rules:   rules,
+		signals: make(map[string]int),
+	}
+
+	if trackClients {
+		acc.clients = make(map[string]*securityClient)
+	}
+
+	return acc
+}
+
+func (acc *securityAccumulator) add(record *domain.LogRecord) {
+	signals := acc.rules.Match(record)
+	if len(signals) > 0 {
+		acc.flagged++
+	}
+
+	for _, signal := range signals {
+		acc.signals[signal]++
+	}
+
+	if acc.clients == nil {
+		return
+	}
+
+	client, exists := acc.clients[record.RemoteAddr]
+	if !exists {
+		client = &securityClient{signals: make(map[string]int)}
+		acc.clients[record.RemoteAddr] = client
+	}
+
+	client.requests++
+
+	if acc.rules.IsAuthFailure(record.Status) {
+		client.authFailures++
+	}
+
+	if record.Method == "POST" {
+		client.posts = append(client.posts, record.TimeLocal)
+	}
+
+	if len(signals) == 0 {
+		return
+	}
+
+	for _, signal := range signals {
+		client.signals[signal]++
+	}
+
+	if len(client.evidence) < evidenceLimit {
+		client.evidence = append(client.evidence, evidenceLine(record))
+	}
+}
+
+func (acc *securityAccumulator) fields() []any {
+	return []any{&acc.flagged, &acc.signals, &acc.clients}
+}
+
+func (acc *securityAccumulator) merge(other accumulator) {
+	source := other.(*securityAccumulator)
+
+	acc.flagged += source.flagged
+	mergeCounts(acc.signals, source.signals)
+
+	for ip, sourceClient := range source.clients {
+		client, exists := acc.clients[ip]
+		if !exists {
+			acc.clients[ip] = sourceClient
+			continue
+		}
+
+		client.requests += sourceClient.requests
+		client.authFailures += sourceClient.authFailures
+		client.posts = append(client.posts, sourceClient.posts...)
+		mergeCounts(client.signals, sourceClient.signals)
+		client.evidence = append(client.evidence, sourceClient.evidence[:min(len(sourceClient.evidence),
+			evidenceLimit-len(client.evidence))]...)
+	}
+}
+
+func (acc *securityAccumulator) fill(report *domain.LogReport) {
+	clients := make([]domain.SecurityClient, 0)
+	signals := maps.Clone(acc.signals)
+	hits := make(map[string]int)
+
+	for ip, client := range acc.clients {
+		clientSignals := maps.Clone(client.signals)
+		behaviour := make([]string, 0)
+
+		if evidence, failures, ok := acc.checkAuthFailures(client); ok {
+			clientSignals[security.SignalAuthFailures] = failures
+			behaviour = append(behaviour, evidence)
+		}
+
+		if evidence, burst, ok := acc.checkPostBurst(client); ok {
+			clientSignals[security.SignalPostBurst] = burst
+			behaviour = append(behaviour, evidence)
+		}
+
+		if len(clientSignals) == 0 {
+			continue
+		}
+
+		for signal, count := range clientSignals {
+			hits[ip] += count
+
+			if signal == security.SignalAuthFailures || signal == security.SignalPostBurst {
+				signals[signal] += count
+			}
+		}
+
+		clients = append(clients, domain.SecurityClient{
+			IP:       ip,
+			Requests: client.requests,
+			Signals:  topValues(clientSignals, 0),
+			Evidence: append(behaviour, client.evidence...),
+		})
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		if hits[clients[i].IP] != hits[clients[j].IP] {
+			return hits[clients[i].IP] > hits[clients[j].IP]
+		}
+
+		return clients[i].IP < clients[j].IP
+	})
+
+	if len(clients) > securityClientLimit {
+		clients = clients[:securityClientLimit]
+	}
+
+	report.Security = domain.SecurityStats{
+		FlaggedRequests: acc.flagged,
+		Signals:         topValues(signals, 0),
+		Clients:         clients,
+	}
+}
+
+func (acc *securityAccumulator) checkAuthFailures(client *securityClient) (evidence string, failures int, ok bool) {
+	if acc.rules.AuthMinRequests <= 0 || client.requests < acc.rules.AuthMinRequests {
+		return "", 0, false
+	}
+
+	ratio := float64(client.authFailures) * 100 / float64(client.requests)
+	if client.authFailures == 0 || ratio < acc.rules.AuthRatio {
+		return "", 0, false
+	}
+
+	statuses := make([]string, 0)
+	for _, status := range acc.rules.AuthFailureStatuses() {
+		statuses = append(statuses, strconv.Itoa(status))
+	}
+
+	return fmt.Sprintf("%d из %d запросов завершились кодами %s (%.2f%%)",
+		client.authFailures, client.requests, strings.Join(statuses, "/"), ratio), client.authFailures, true
+}
+
+func (acc *securityAccumulator) checkPostBurst(client *securityClient) (evidence string, burst int, ok bool) {
+	if acc.rules.BurstWindow <= 0 || acc.rules.BurstMinRequests <= 0 || len(client.posts) < acc.rules.BurstMinRequests {
+		return "", 0, false
+	}
+
+	posts := slices.Clone(client.posts)
+
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].Before(posts[j])
+	})
+
+	burstStart, first := time.Time{}, 0
+
+	for last, post := range posts {
+		for post.Sub(posts[first]) >= acc.rules.BurstWindow {
+			first++
+		}
+
+		if count := last - first + 1; count > burst {
+			burst, burstStart = count, posts[first]
+		}
+	}
+
+	if burst < acc.rules.BurstMinRequests {
+		return "", 0, false
+	}
+
+	return fmt.Sprintf("%d POST-запросов за %s начиная с %s",
+		burst, acc.rules.BurstWindow, burstStart.Format("02.01.2006 15:04:05")), burst, true
+}
+
+func evidenceLine(record *domain.LogRecord) string {
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %d \"%s\" \"%s\"",
+		record.RemoteAddr, record.RemoteUser, record.TimeLocal.Format("02/Jan/2006:15:04:05 -0700"),
+		record.Method, record.URL, record.ProtocolVersion, record.Status, record.BodyBytesSent,
+		record.Referer, record.UserAgent)
+}
@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	pathLimit = 10
+	pathDepth = 5
+)
+
+type visit struct {
+	time time.Time
+	url  string
+}
+
+type visitState struct {
+	Time time.Time `json:"time"`
+	URL  string    `json:"url"`
+}
+
+func (entry visit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(visitState{Time: entry.time, URL: entry.url})
+}
+
+func (entry *visit) UnmarshalJSON(data []byte) error {
+	var state visitState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	entry.time, entry.url = state.Time, state.URL
+
+	return nil
+}
+
+type clientVisits map[string][]visit
+
+func (visits clientVisits) add(record *domain.LogRecord) {
+	key := clientKey(record)
+	visits[key] = append(visits[key], visit{time: record.TimeLocal, url: record.URL})
+}
+
+func (visits clientVisits) sorted(key string) []visit {
+	ordered := visits[key]
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].time.Before(ordered[j].time)
+	})
+
+	return ordered
+}
+
+func (visits clientVisits) merge(other clientVisits) {
+	for key, source := range other {
+		visits[key] = append(visits[key], source...)
+	}
+}
+
+func clientKey(record *domain.LogRecord) string {
+	if record.RemoteUser != "" && record.RemoteUser != "-" {
+		return "user:" + record.RemoteUser
+	}
+
+	return record.RemoteAddr + "|" + record.UserAgent
+}
+
+func splitSessions(visits []visit, gap time.Duration) [][]visit {
+	sessions := make([][]visit, 0)
+	start := 0
+
+	for i := 1; i <= len(visits); i++ {
+		if i == len(visits) || visits[i].time.Sub(visits[i-1].time) > gap {
+			sessions = append(sessions, visits[start:i])
+			start = i
+		}
+	}
+
+	return sessions
+}
+
+type sessionAccumulator struct {
+	gap    time.Duration
+	visits clientVisits
+}
+
+func newSessionAccumulator(gap time.Duration) *sessionAccumulator {
+	if gap <= 0 {
+		gap = domain.DefaultSessionGap
+	}
+
+	return &sessionAccumulator{
+		gap:    gap,
+		visits: make(clientVisits),
+	}
+}
+
+func (acc *sessionAccumulator) add(record *domain.LogRecord) {
+	acc.visits.add(record)
+}
+
+func (acc *sessionAccumulator) fields() []any {
+	return []any{&acc.visits}
+}
+
+func (acc *sessionAccumulator) merge(other accumulator) {
+	acc.visits.merge(other.(*sessionAccumulator).visits)
+}
+
+func (acc *sessionAccumulator) fill(report *domain.LogReport) {
+	stats := domain.SessionStats{Gap: acc.gap, Visitors: len(acc.visits)}
+	entries := make(map[string]int)
+	exits := make(map[string]int)
+	paths := make(map[string]int)
+
+	var totalDuration time.Duration
+
+	totalPages := 0
+
+	for key := range acc.visits {
+		for _, session := range splitSessions(acc.visits.sorted(key), acc.gap) {
+			stats.Sessions++
+			totalPages += len(session)
+			totalDuration += session[len(session)-1].time.Sub(session[0].time)
+			entries[session[0].url]++
+			exits[session[len(session)-1].url]++
+			paths[navigationPath(session)]++
+
+			if len(session) == 1 {
+				stats.BounceSessions++
+			}
+		}
+	}
+
+	if stats.Sessions > 0 {
+		stats.AvgDuration = totalDuration / time.Duration(stats.Sessions)
+		stats.AvgPages = float64(totalPages) / float64(stats.Sessions)
+	}
+
+	stats.TopEntryURLs = topValues(entries, topLimit)
+	stats.TopExitURLs = topValues(exits, topLimit)
+	stats.TopPaths = topValues(paths, pathLimit)
+	report.Sessions = stats
+}
+
+func navigationPath(session []visit) string {
+	steps := make([]string, 0, pathDepth)
+
+	for _, visit := range session {
+		if len(steps) > 0 && steps[len(steps)-1] == visit.url {
+			continue
+		}
+
+		if len(steps) == pathDepth {
+			steps = append(steps, "…")
+			break
+		}
+
+		steps = append(steps, visit.url)
+	}
+
+	return strings.Join(steps, " → ")
+}
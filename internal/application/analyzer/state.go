@@ -0,0 +1,234 @@
+package analyzer
+
+import (
+	security "analyzer/internal/application/security"
+	domain "analyzer/internal/domain"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+func (analyzer *LogAnalyzer) State(records []domain.LogRecord, config *domain.Config) (domain.AnalyzerState, error) {
+	return analyzer.StateBatches(singleBatch(records), config)
+}
+
+func (analyzer *LogAnalyzer) StateBatches(batches domain.RecordBatches, config *domain.Config) (domain.AnalyzerState, error) {
+	rules, err := analyzer.getSecurityRules(config)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	accumulators, total, err := analyzer.accumulate(batches, config, rules)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	state := domain.AnalyzerState{
+		Version:       domain.StateVersion,
+		Settings:      stateSettings(config),
+		FileNames:     analyzer.getFileNames(config),
+		TotalRequests: total,
+	}
+
+	if urlName := analyzer.getURLNames(config); urlName != "" {
+		state.URLNames = []string{urlName}
+	}
+
+	return encodeState(state, accumulators)
+}
+
+func (analyzer *LogAnalyzer) MergeStates(states []domain.AnalyzerState, config *domain.Config) (domain.AnalyzerState, error) {
+	if len(states) == 0 {
+		return domain.AnalyzerState{}, fmt.Errorf("нет состояний для объединения")
+	}
+
+	settings := states[0].Settings
+	config = withSettings(config, settings)
+
+	rules, err := analyzer.getSecurityRules(config)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	merged := domain.AnalyzerState{
+		Version:   domain.StateVersion,
+		Settings:  settings,
+		FileNames: make([]string, 0),
+	}
+
+	var accumulators []accumulator
+
+	for ind := range states {
+		state := &states[ind]
+
+		if !reflect.DeepEqual(state.Settings, settings) {
+			return domain.AnalyzerState{}, fmt.Errorf("состояние %d собрано с другими настройками анализа", ind+1)
+		}
+
+		partial, err := analyzer.decodeState(state, config, rules)
+		if err != nil {
+			return domain.AnalyzerState{}, err
+		}
+
+		if accumulators == nil {
+			accumulators = partial
+		} else {
+			for ind, accumulator := range accumulators {
+				accumulator.merge(partial[ind])
+			}
+		}
+
+		merged.FileNames = append(merged.FileNames, state.FileNames...)
+		merged.URLNames = append(merged.URLNames, state.URLNames...)
+		merged.TotalRequests += state.TotalRequests
+	}
+
+	return encodeState(merged, accumulators)
+}
+
+func (analyzer *LogAnalyzer) AnalyzeState(state *domain.AnalyzerState, config *domain.Config) (domain.LogReport, error) {
+	config = withSettings(config, state.Settings)
+	report := analyzer.initReport(config, state.TotalRequests)
+	report.FileNames = state.FileNames
+	report.URLName = strings.Join(state.URLNames, ", ")
+
+	if state.TotalRequests == 0 {
+		return *report, fmt.Errorf("нет записей для анализа")
+	}
+
+	rules, err := analyzer.getSecurityRules(config)
+	if err != nil {
+		return *report, err
+	}
+
+	accumulators, err := analyzer.decodeState(state, config, rules)
+	if err != nil {
+		return *report, err
+	}
+
+	for _, accumulator := range accumulators {
+		accumulator.fill(report)
+	}
+
+	report.Anomalies = analyzer.detector.Detect(report, config.Anomaly)
+
+	return *report, nil
+}
+
+func (analyzer *LogAnalyzer) decodeState(state *domain.AnalyzerState, config *domain.Config,
+	rules *security.Rules) ([]accumulator, error) {
+	if state.Version != domain.StateVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия состояния: %d", state.Version)
+	}
+
+	accumulators := analyzer.newAccumulators(config, rules)
+	if len(state.Accumulators) != len(accumulators) {
+		return nil, fmt.Errorf("повреждённое состояние: ожидалось %d счётчиков, получено %d",
+			len(accumulators), len(state.Accumulators))
+	}
+
+	for ind, accumulator := range accumulators {
+		if err := decodeFields(state.Accumulators[ind], accumulator.fields()); err != nil {
+			return nil, fmt.Errorf("повреждённое состояние: %v", err)
+		}
+	}
+
+	return accumulators, nil
+}
+
+func encodeState(state domain.AnalyzerState, accumulators []accumulator) (domain.AnalyzerState, error) {
+	state.Accumulators = make([]json.RawMessage, 0, len(accumulators))
+
+	for _, accumulator := range accumulators {
+		data, err := json.Marshal(accumulator.fields())
+		if err != nil {
+			return domain.AnalyzerState{}, fmt.Errorf("не удалось сохранить состояние: %v", err)
+		}
+
+		state.Accumulators = append(state.Accumulators, data)
+	}
+
+	return state, nil
+}
+
+func decodeFields(data json.RawMessage, fields []any) error {
+	var values []json.RawMessage
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	if len(values) != len(fields) {
+		return fmt.Errorf("ожидалось %d полей, получено %d", len(fields), len(values))
+	}
+
+	for ind, field := range fields {
+		if err := json.Unmarshal(values[ind], field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stateSettings(config *domain.Config) domain.StateSettings {
+	return domain.StateSettings{
+		From:          config.From,
+		To:            config.To,
+		Approx:        config.Approx,
+		SiteHosts:     config.SiteHosts,
+		BucketSize:    config.BucketSize,
+		SessionGap:    config.SessionGap,
+		Funnel:        config.Funnel,
+		FunnelWindow:  config.FunnelWindow,
+		ProfileClient: config.ProfileClient,
+		Anonymize:     config.Anonymize.Mode,
+
+		URL:            urlSettings(&config.URLOptions),
+		ClientIP:       config.ClientIP.Mode,
+		TrustedProxies: nilIfEmpty(config.ClientIP.TrustedProxies),
+		UserAgentRules: config.UserAgentRules,
+	}
+}
+
+func urlSettings(options *domain.URLOptions) domain.URLSettings {
+	routes := make([]string, 0, len(options.Routes))
+	for _, route := range options.Routes {
+		routes = append(routes, route.Template)
+	}
+
+	return domain.URLSettings{
+		Lowercase:   options.Lowercase,
+		Decode:      options.Decode,
+		CollapseIDs: options.CollapseIDs,
+		StripQuery:  nilIfEmpty(options.StripQuery),
+		KeepQuery:   nilIfEmpty(options.KeepQuery),
+		Routes:      nilIfEmpty(routes),
+	}
+}
+
+func nilIfEmpty[T any](values []T) []T {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values
+}
+
+func withSettings(config *domain.Config, settings domain.StateSettings) *domain.Config {
+	merged := *config
+
+	merged.From = settings.From
+	merged.To = settings.To
+	merged.Approx = settings.Approx
+	merged.SiteHosts = settings.SiteHosts
+	merged.BucketSize = settings.BucketSize
+	merged.SessionGap = settings.SessionGap
+	merged.Funnel = settings.Funnel
+	merged.FunnelWindow = settings.FunnelWindow
+	merged.ProfileClient = settings.ProfileClient
+	merged.Anonymize.Mode = settings.Anonymize
+
+	return &merged
+}
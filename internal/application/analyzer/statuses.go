@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"fmt"
+)
+
+const statusClassCount = 5
+
+type statusClassAccumulator struct {
+	counts  [statusClassCount]int
+	urls    [statusClassCount]counter
+	clients [statusClassCount]counter
+}
+
+func newStatusClassAccumulator(capacity int) *statusClassAccumulator {
+	acc := &statusClassAccumulator{}
+
+	for i := range statusClassCount {
+		acc.urls[i] = newCounter(capacity)
+		acc.clients[i] = newCounter(capacity)
+	}
+
+	return acc
+}
+
+func (acc *statusClassAccumulator) add(record *domain.LogRecord) {
+	class, ok := statusClassIndex(record.Status)
+	if !ok {
+		return
+	}
+
+	acc.counts[class]++
+	acc.urls[class].add(record.URL, 1)
+	acc.clients[class].add(record.RemoteAddr, 1)
+}
+
+func (acc *statusClassAccumulator) fields() []any {
+	var urls, clients [statusClassCount]*counterState
+
+	for i := range statusClassCount {
+		urls[i], clients[i] = &counterState{acc.urls[i]}, &counterState{acc.clients[i]}
+	}
+
+	return []any{&acc.counts, &urls, &clients}
+}
+
+func (acc *statusClassAccumulator) merge(other accumulator) {
+	source := other.(*statusClassAccumulator)
+
+	for i := range statusClassCount {
+		acc.counts[i] += source.counts[i]
+		acc.urls[i].merge(source.urls[i])
+		acc.clients[i].merge(source.clients[i])
+	}
+}
+
+func (acc *statusClassAccumulator) fill(report *domain.LogReport) {
+	report.StatusClasses = make([]domain.StatusClass, 0, statusClassCount)
+
+	for i := range statusClassCount {
+		if acc.counts[i] == 0 {
+			continue
+		}
+
+		report.StatusClasses = append(report.StatusClasses, domain.StatusClass{
+			Class:      fmt.Sprintf("%dxx", i+1),
+			Count:      acc.counts[i],
+			TopURLs:    topValues(acc.urls[i].counts(), topLimit),
+			TopClients: topValues(acc.clients[i].counts(), topLimit),
+		})
+	}
+
+	report.ClientErrors = acc.counts[3]
+	report.ServerErrors = acc.counts[4]
+}
+
+func statusClassIndex(status int) (int, bool) {
+	class := status/100 - 1
+	if class < 0 || class >= statusClassCount {
+		return 0, false
+	}
+
+	return class, true
+}
+
+func isClientError(status int) bool {
+	return status >= 400 && status < 500
+}
+
+func isServerError(status int) bool {
+	return status >= 500 && status < 600
+}
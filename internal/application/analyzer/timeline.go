@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"time"
+)
+
+type timelineAccumulator struct {
+	bucketSize time.Duration
+	buckets    map[int64]*domain.TimeBucket
+	clients    *counterGroup[int64]
+}
+
+func newTimelineAccumulator(bucketSize time.Duration, capacity int) *timelineAccumulator {
+	if bucketSize <= 0 {
+		bucketSize = domain.DefaultBucketSize
+	}
+
+	return &timelineAccumulator{
+		bucketSize: bucketSize,
+		buckets:    make(map[int64]*domain.TimeBucket),
+		clients:    newCounterGroup[int64](capacity),
+	}
+}
+
+func (acc *timelineAccumulator) add(record *domain.LogRecord) {
+	start := slotStart(record.TimeLocal, acc.bucketSize)
+
+	bucket, exists := acc.buckets[start]
+	if !exists {
+		bucket = &domain.TimeBucket{Start: time.Unix(start, 0).UTC()}
+		acc.buckets[start] = bucket
+	}
+
+	acc.clients.get(start).add(record.RemoteAddr, 1)
+
+	bucket.Requests++
+	bucket.Bytes += int64(record.BodyBytesSent)
+
+	if isClientError(record.Status) {
+		bucket.ClientErrors++
+	}
+
+	if isServerError(record.Status) {
+		bucket.ServerErrors++
+	}
+}
+
+func (acc *timelineAccumulator) fields() []any {
+	return []any{&acc.buckets, acc.clients}
+}
+
+func (acc *timelineAccumulator) merge(other accumulator) {
+	source := other.(*timelineAccumulator)
+
+	for start, sourceBucket := range source.buckets {
+		bucket, exists := acc.buckets[start]
+		if !exists {
+			bucket = &domain.TimeBucket{Start: sourceBucket.Start}
+			acc.buckets[start] = bucket
+		}
+
+		bucket.Requests += sourceBucket.Requests
+		bucket.Bytes += sourceBucket.Bytes
+		bucket.ClientErrors += sourceBucket.ClientErrors
+		bucket.ServerErrors += sourceBucket.ServerErrors
+	}
+
+	acc.clients.merge(source.clients)
+}
+
+func (acc *timelineAccumulator) fill(report *domain.LogReport) {
+	report.BucketSize, report.Timeline = acc.timeline()
+}
+
+func (acc *timelineAccumulator) timeline() (time.Duration, []domain.TimeBucket) {
+	layout, found := newBucketLayout(acc.buckets, acc.bucketSize)
+	timeline := make([]domain.TimeBucket, 0)
+
+	if !found {
+		return layout.size, timeline
+	}
+
+	buckets := make(map[int64]*domain.TimeBucket)
+	clients := newCounterGroup[int64](acc.clients.capacity)
+
+	for slot, source := range acc.buckets {
+		start := layout.start(slot)
+
+		bucket, exists := buckets[start]
+		if !exists {
+			bucket = &domain.TimeBucket{Start: time.Unix(start, 0).UTC()}
+			buckets[start] = bucket
+		}
+
+		bucket.Requests += source.Requests
+		bucket.Bytes += source.Bytes
+		bucket.ClientErrors += source.ClientErrors
+		bucket.ServerErrors += source.ServerErrors
+	}
+
+	for slot, values := range acc.clients.counters {
+		clients.get(layout.start(slot)).merge(values)
+	}
+
+	for start := layout.first; start <= layout.last; start += layout.step() {
+		bucket, exists := buckets[start]
+		if !exists {
+			timeline = append(timeline, domain.TimeBucket{Start: time.Unix(start, 0).UTC()})
+			continue
+		}
+
+		if top := topValues(clients.get(start).counts(), 1); len(top) > 0 {
+			bucket.TopClient = top[0].Value
+			bucket.TopClientRequests = top[0].Count
+		}
+
+		timeline = append(timeline, *bucket)
+	}
+
+	return layout.size, timeline
+}
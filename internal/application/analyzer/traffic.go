@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	domain "analyzer/internal/domain"
+	"sort"
+	"time"
+)
+
+type trafficAccumulator struct {
+	bucketSize time.Duration
+	totalBytes int64
+	buckets    map[int64]int64
+	urls       counter
+	clients    counter
+}
+
+func newTrafficAccumulator(bucketSize time.Duration, capacity int) *trafficAccumulator {
+	if bucketSize <= 0 {
+		bucketSize = domain.DefaultBucketSize
+	}
+
+	return &trafficAccumulator{
+		bucketSize: bucketSize,
+		buckets:    make(map[int64]int64),
+		urls:       newCounter(capacity),
+		clients:    newCounter(capacity),
+	}
+}
+
+func (acc *trafficAccumulator) add(record *domain.LogRecord) {
+	bytes := int64(record.BodyBytesSent)
+
+	acc.totalBytes += bytes
+	acc.buckets[slotStart(record.TimeLocal, acc.bucketSize)] += bytes
+	acc.urls.add(record.URL, record.BodyBytesSent)
+	acc.clients.add(record.RemoteAddr, record.BodyBytesSent)
+}
+
+func (acc *trafficAccumulator) fields() []any {
+	return []any{&acc.totalBytes, &acc.buckets, &counterState{acc.urls}, &counterState{acc.clients}}
+}
+
+func (acc *trafficAccumulator) merge(other accumulator) {
+	source := other.(*trafficAccumulator)
+
+	acc.totalBytes += source.totalBytes
+	mergeBytes(acc.buckets, source.buckets)
+	acc.urls.merge(source.urls)
+	acc.clients.merge(source.clients)
+}
+
+func (acc *trafficAccumulator) fill(report *domain.LogReport) {
+	report.Traffic = domain.TrafficStats{
+		TotalBytes: acc.totalBytes,
+		TopURLs:    topBytes(acc.urls.counts(), topLimit),
+		TopClients: topBytes(acc.clients.counts(), topLimit),
+	}
+
+	layout, found := newBucketLayout(acc.buckets, acc.bucketSize)
+	if !found {
+		return
+	}
+
+	buckets := make(map[int64]int64)
+	for slot, bytes := range acc.buckets {
+		buckets[layout.start(slot)] += bytes
+	}
+
+	peakStart, found := int64(0), false
+
+	for start, bytes := range buckets {
+		peak := report.Traffic.PeakBytes
+		if !found || bytes > peak || (bytes == peak && start < peakStart) {
+			report.Traffic.PeakBytes = bytes
+			peakStart, found = start, true
+		}
+	}
+
+	report.Traffic.PeakBucketStart = time.Unix(peakStart, 0).UTC()
+}
+
+func mergeBytes[K comparable](target, source map[K]int64) {
+	for key, bytes := range source {
+		target[key] += bytes
+	}
+}
+
+func topBytes(counts map[string]int, limit int) []domain.ByteCount {
+	values := make([]domain.ByteCount, 0, len(counts))
+	for value, bytes := range counts {
+		values = append(values, domain.ByteCount{Value: value, Bytes: int64(bytes)})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Bytes != values[j].Bytes {
+			return values[i].Bytes > values[j].Bytes
+		}
+
+		return values[i].Value < values[j].Value
+	})
+
+	if len(values) > limit {
+		values = values[:limit]
+	}
+
+	return values
+}
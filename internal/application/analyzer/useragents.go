@@ -0,0 +1,78 @@
+package analyzer
+
+import domain "analyzer/internal/domain"
+
+const unknownValue = "Неизвестно"
+
+type userAgentAccumulator struct {
+	browsers    map[string]int
+	systems     map[string]int
+	devices     map[string]int
+	bots        map[string]int
+	agents      counter
+	botRequests int
+	total       int
+}
+
+func newUserAgentAccumulator(capacity int) *userAgentAccumulator {
+	return &userAgentAccumulator{
+		agents:   newCounter(capacity),
+		browsers: make(map[string]int),
+		systems:  make(map[string]int),
+		devices:  make(map[string]int),
+		bots:     make(map[string]int),
+	}
+}
+
+func (acc *userAgentAccumulator) add(record *domain.LogRecord) {
+	acc.total++
+	acc.agents.add(valueOrUnknown(record.UserAgent), 1)
+
+	if record.Agent.Bot {
+		acc.botRequests++
+		acc.bots[record.Agent.BotName]++
+
+		return
+	}
+
+	acc.browsers[valueOrUnknown(record.Agent.Browser)]++
+	acc.systems[valueOrUnknown(record.Agent.OS)]++
+	acc.devices[valueOrUnknown(record.Agent.Device)]++
+}
+
+func (acc *userAgentAccumulator) fields() []any {
+	return []any{&acc.browsers, &acc.systems, &acc.devices, &acc.bots, &counterState{acc.agents}, &acc.botRequests, &acc.total}
+}
+
+func (acc *userAgentAccumulator) merge(other accumulator) {
+	source := other.(*userAgentAccumulator)
+
+	mergeCounts(acc.browsers, source.browsers)
+	mergeCounts(acc.systems, source.systems)
+	mergeCounts(acc.devices, source.devices)
+	mergeCounts(acc.bots, source.bots)
+	acc.agents.merge(source.agents)
+	acc.botRequests += source.botRequests
+	acc.total += source.total
+}
+
+func (acc *userAgentAccumulator) fill(report *domain.LogReport) {
+	report.UserAgents = domain.UserAgentStats{
+		TopBrowsers:         topValues(acc.browsers, topLimit),
+		TopOperatingSystems: topValues(acc.systems, topLimit),
+		DeviceTypes:         topValues(acc.devices, 0),
+		TopBots:             topValues(acc.bots, topLimit),
+		TopAgents:           topValues(acc.agents.counts(), topLimit),
+		BotRequests:         acc.botRequests,
+		HumanRequests:       acc.total - acc.botRequests,
+	}
+	report.Approximation.UserAgentError = acc.agents.maxError()
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return unknownValue
+	}
+
+	return value
+}
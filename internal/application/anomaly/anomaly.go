@@ -0,0 +1,215 @@
+package anomaly
+
+import (
+	domain "analyzer/internal/domain"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	MetricRequests       = "Запросы"
+	MetricErrorRate      = "Доля ошибок"
+	MetricDominantClient = "Доминирующий клиент"
+
+	madScale            = 1.4826
+	minHistory          = 3
+	relativeScaleFloor  = 0.1
+	errorRateScaleFloor = 1.0
+	dominantShare       = 50.0
+	minDominantRequests = 10
+	minEdgeCoverage     = 0.9
+)
+
+type Detector struct{}
+
+func NewDetector() *Detector {
+	return &Detector{}
+}
+
+func (detector *Detector) Detect(report *domain.LogReport, options domain.AnomalyOptions) []domain.Anomaly {
+	if options.Threshold <= 0 {
+		options.Threshold = domain.DefaultAnomalyThreshold
+	}
+
+	if options.Window < minHistory {
+		options.Window = domain.DefaultAnomalyWindow
+	}
+
+	anomalies := make([]domain.Anomaly, 0)
+	timeline := completeBuckets(report)
+
+	for i := minHistory; i < len(timeline); i++ {
+		history := timeline[max(0, i-options.Window):i]
+		bucket := &timeline[i]
+
+		if anomaly, ok := detector.checkRequests(bucket, history, options.Threshold); ok {
+			anomalies = append(anomalies, detector.withWindow(anomaly, bucket, report))
+		}
+
+		if anomaly, ok := detector.checkErrorRate(bucket, history, options.Threshold); ok {
+			anomalies = append(anomalies, detector.withWindow(anomaly, bucket, report))
+		}
+
+		if anomaly, ok := detector.checkDominantClient(bucket, history); ok {
+			anomalies = append(anomalies, detector.withWindow(anomaly, bucket, report))
+		}
+	}
+
+	return anomalies
+}
+
+func completeBuckets(report *domain.LogReport) []domain.TimeBucket {
+	timeline := report.Timeline
+
+	if len(timeline) > 0 && !report.FirstRequest.IsZero() {
+		first := &timeline[0]
+		if coverage(first.Start.Add(report.BucketSize).Sub(report.FirstRequest), report.BucketSize) < minEdgeCoverage {
+			timeline = timeline[1:]
+		}
+	}
+
+	if len(timeline) > 0 && !report.LastRequest.IsZero() {
+		last := &timeline[len(timeline)-1]
+		if coverage(report.LastRequest.Add(time.Second).Sub(last.Start), report.BucketSize) < minEdgeCoverage {
+			timeline = timeline[:len(timeline)-1]
+		}
+	}
+
+	return timeline
+}
+
+func coverage(covered, bucketSize time.Duration) float64 {
+	if bucketSize <= 0 {
+		return 1
+	}
+
+	return float64(covered) / float64(bucketSize)
+}
+
+func (detector *Detector) checkRequests(bucket *domain.TimeBucket, history []domain.TimeBucket, threshold float64) (domain.Anomaly, bool) {
+	values := make([]float64, 0, len(history))
+	for _, previous := range history {
+		values = append(values, float64(previous.Requests))
+	}
+
+	observed := float64(bucket.Requests)
+	expected, score := robustScore(values, observed, 1)
+
+	if math.Abs(score) <= threshold {
+		return domain.Anomaly{}, false
+	}
+
+	description := fmt.Sprintf("Всплеск трафика: %.0f запросов при ожидаемых %.0f", observed, expected)
+	if score < 0 {
+		description = fmt.Sprintf("Падение трафика: %.0f запросов при ожидаемых %.0f", observed, expected)
+	}
+
+	return domain.Anomaly{
+		Metric:      MetricRequests,
+		Kind:        domain.MetricCount,
+		Expected:    expected,
+		Observed:    observed,
+		Description: description,
+	}, true
+}
+
+func (detector *Detector) checkErrorRate(bucket *domain.TimeBucket, history []domain.TimeBucket, threshold float64) (domain.Anomaly, bool) {
+	if bucket.Requests == 0 {
+		return domain.Anomaly{}, false
+	}
+
+	values := make([]float64, 0, len(history))
+
+	for _, previous := range history {
+		if previous.Requests > 0 {
+			values = append(values, errorRate(&previous))
+		}
+	}
+
+	if len(values) < minHistory {
+		return domain.Anomaly{}, false
+	}
+
+	observed := errorRate(bucket)
+	expected, score := robustScore(values, observed, errorRateScaleFloor)
+
+	if score <= threshold {
+		return domain.Anomaly{}, false
+	}
+
+	return domain.Anomaly{
+		Metric:      MetricErrorRate,
+		Kind:        domain.MetricPercent,
+		Expected:    expected,
+		Observed:    observed,
+		Description: fmt.Sprintf("Рост доли ошибок: %.2f%% при ожидаемых %.2f%%", observed, expected),
+	}, true
+}
+
+func (detector *Detector) checkDominantClient(bucket *domain.TimeBucket, history []domain.TimeBucket) (domain.Anomaly, bool) {
+	if bucket.Requests < minDominantRequests || bucket.TopClient == "" {
+		return domain.Anomaly{}, false
+	}
+
+	observed := float64(bucket.TopClientRequests) * 100 / float64(bucket.Requests)
+	if observed < dominantShare {
+		return domain.Anomaly{}, false
+	}
+
+	for _, previous := range history {
+		if previous.TopClient == bucket.TopClient {
+			return domain.Anomaly{}, false
+		}
+	}
+
+	return domain.Anomaly{
+		Metric:      MetricDominantClient,
+		Kind:        domain.MetricPercent,
+		Expected:    0,
+		Observed:    observed,
+		Description: fmt.Sprintf("Новый доминирующий клиент %s: %.2f%% запросов интервала", bucket.TopClient, observed),
+	}, true
+}
+
+func (detector *Detector) withWindow(anomaly domain.Anomaly, bucket *domain.TimeBucket, report *domain.LogReport) domain.Anomaly {
+	anomaly.Start = bucket.Start
+	anomaly.End = bucket.Start.Add(report.BucketSize)
+
+	return anomaly
+}
+
+func robustScore(values []float64, observed, scaleFloor float64) (expected, score float64) {
+	expected = median(values)
+
+	deviations := make([]float64, 0, len(values))
+	for _, value := range values {
+		deviations = append(deviations, math.Abs(value-expected))
+	}
+
+	scale := madScale * median(deviations)
+	scale = math.Max(scale, math.Max(scaleFloor, relativeScaleFloor*math.Abs(expected)))
+
+	return expected, (observed - expected) / scale
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+func errorRate(bucket *domain.TimeBucket) float64 {
+	return float64(bucket.ClientErrors+bucket.ServerErrors) * 100 / float64(bucket.Requests)
+}
@@ -0,0 +1,96 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTimeline(requests []int) []domain.TimeBucket {
+	start := time.Date(2024, 8, 31, 0, 0, 0, 0, time.UTC)
+	timeline := make([]domain.TimeBucket, 0, len(requests))
+
+	for i, count := range requests {
+		timeline = append(timeline, domain.TimeBucket{
+			Start:             start.Add(time.Duration(i) * time.Hour),
+			Requests:          count,
+			ClientErrors:      count / 50,
+			TopClient:         "10.0.0.1",
+			TopClientRequests: count / 10,
+		})
+	}
+
+	return timeline
+}
+
+func TestDetect(t *testing.T) {
+	detector := NewDetector()
+	options := domain.AnomalyOptions{Threshold: 3.5, Window: 24}
+
+	t.Run("StableTraffic", func(t *testing.T) {
+		report := &domain.LogReport{BucketSize: time.Hour, Timeline: createTimeline([]int{100, 104, 98, 101, 97, 103, 99})}
+
+		assert.Empty(t, detector.Detect(report, options))
+	})
+
+	t.Run("SpikeAndDrop", func(t *testing.T) {
+		report := &domain.LogReport{BucketSize: time.Hour, Timeline: createTimeline([]int{100, 104, 98, 101, 500, 99, 0})}
+
+		anomalies := detector.Detect(report, options)
+
+		require.Len(t, anomalies, 2)
+		assert.Equal(t, MetricRequests, anomalies[0].Metric)
+		assert.Equal(t, float64(500), anomalies[0].Observed)
+		assert.InDelta(t, 100.5, anomalies[0].Expected, 0.001)
+		assert.Equal(t, report.Timeline[4].Start, anomalies[0].Start)
+		assert.Equal(t, report.Timeline[4].Start.Add(time.Hour), anomalies[0].End)
+		assert.Contains(t, anomalies[0].Description, "Всплеск")
+		assert.Equal(t, float64(0), anomalies[1].Observed)
+		assert.Contains(t, anomalies[1].Description, "Падение")
+	})
+
+	t.Run("PartialEdgeBuckets", func(t *testing.T) {
+		report := &domain.LogReport{BucketSize: time.Hour, Timeline: createTimeline([]int{30, 100, 104, 98, 101, 97, 103, 15})}
+		report.FirstRequest = report.Timeline[0].Start.Add(40 * time.Minute)
+		report.LastRequest = report.Timeline[7].Start.Add(9*time.Minute + 59*time.Second)
+
+		assert.Empty(t, detector.Detect(report, options), "Неполные крайние интервалы не считаются аномалиями")
+
+		report.LastRequest = report.Timeline[7].Start.Add(59*time.Minute + 59*time.Second)
+
+		anomalies := detector.Detect(report, options)
+
+		require.Len(t, anomalies, 1)
+		assert.Contains(t, anomalies[0].Description, "Падение")
+		assert.Equal(t, report.Timeline[7].Start, anomalies[0].Start)
+	})
+
+	t.Run("ErrorRate", func(t *testing.T) {
+		timeline := createTimeline([]int{100, 100, 100, 100, 100})
+		timeline[4].ServerErrors = 30
+
+		anomalies := detector.Detect(&domain.LogReport{BucketSize: time.Hour, Timeline: timeline}, options)
+
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, MetricErrorRate, anomalies[0].Metric)
+		assert.InDelta(t, 2.0, anomalies[0].Expected, 0.001)
+		assert.InDelta(t, 32.0, anomalies[0].Observed, 0.001)
+	})
+
+	t.Run("DominantClient", func(t *testing.T) {
+		timeline := createTimeline([]int{100, 100, 100, 100, 100})
+		timeline[4].TopClient = "203.0.113.7"
+		timeline[4].TopClientRequests = 80
+
+		anomalies := detector.Detect(&domain.LogReport{BucketSize: time.Hour, Timeline: timeline}, options)
+
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, MetricDominantClient, anomalies[0].Metric)
+		assert.InDelta(t, 80.0, anomalies[0].Observed, 0.001)
+		assert.Contains(t, anomalies[0].Description, "203.0.113.7")
+	})
+}
@@ -0,0 +1,167 @@
+package anonymizer
+
+import (
+	domain "analyzer/internal/domain"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+const (
+	RedactedValue = "REDACTED"
+
+	pseudonymLength = 12
+	keySize         = 32
+	ipv4Prefix      = 24
+	ipv6Prefix      = 48
+)
+
+type LogAnonymizer struct{}
+
+func NewLogAnonymizer() *LogAnonymizer {
+	return &LogAnonymizer{}
+}
+
+func (anonymizer *LogAnonymizer) Anonymize(records []domain.LogRecord, config *domain.Config) ([]domain.LogRecord, error) {
+	options := &config.Anonymize
+
+	if options.Mode == "" && options.RedactParams == nil {
+		return records, nil
+	}
+
+	masker, err := newMasker(options)
+	if err != nil {
+		return nil, err
+	}
+
+	for ind := range records {
+		masker.apply(&records[ind])
+	}
+
+	return records, nil
+}
+
+type masker struct {
+	mode   string
+	key    []byte
+	params *regexp.Regexp
+	cache  map[string]string
+}
+
+func newMasker(options *domain.AnonymizeOptions) (*masker, error) {
+	key := options.Key
+
+	if len(key) == 0 {
+		key = make([]byte, keySize)
+
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("не удалось сгенерировать ключ анонимизации: %v", err)
+		}
+	}
+
+	return &masker{
+		mode:   options.Mode,
+		key:    key,
+		params: options.RedactParams,
+		cache:  make(map[string]string),
+	}, nil
+}
+
+func (masker *masker) apply(record *domain.LogRecord) {
+	if masker.mode != "" {
+		record.RemoteAddr = masker.address(record.RemoteAddr)
+		record.PeerAddr = masker.address(record.PeerAddr)
+		record.RealIP = masker.address(record.RealIP)
+		record.ForwardedFor = masker.addressList(record.ForwardedFor)
+		record.RemoteUser = masker.user(record.RemoteUser)
+	}
+
+	if masker.params != nil {
+		record.URL = masker.redact(record.URL)
+		record.Referer = masker.redact(record.Referer)
+	}
+}
+
+func (masker *masker) address(value string) string {
+	if value == "" {
+		return value
+	}
+
+	if result, exists := masker.cache[value]; exists {
+		return result
+	}
+
+	var result string
+
+	addr, err := netip.ParseAddr(value)
+
+	switch {
+	case masker.mode == domain.AnonymizeHMAC || err != nil:
+		result = "ip-" + masker.pseudonym(value)
+	case addr.Is4() || addr.Is4In6():
+		result = netip.PrefixFrom(addr.Unmap(), ipv4Prefix).Masked().Addr().String()
+	default:
+		result = netip.PrefixFrom(addr, ipv6Prefix).Masked().Addr().String()
+	}
+
+	masker.cache[value] = result
+
+	return result
+}
+
+func (masker *masker) addressList(value string) string {
+	if value == "" {
+		return value
+	}
+
+	hops := strings.Split(value, ",")
+	for i, hop := range hops {
+		hops[i] = masker.address(strings.TrimSpace(hop))
+	}
+
+	return strings.Join(hops, ", ")
+}
+
+func (masker *masker) user(value string) string {
+	if value == "" || value == "-" {
+		return value
+	}
+
+	return "user-" + masker.pseudonym(value)
+}
+
+func (masker *masker) pseudonym(value string) string {
+	mac := hmac.New(sha256.New, masker.key)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
+
+func (masker *masker) redact(rawURL string) string {
+	base, query, found := strings.Cut(rawURL, "?")
+	if !found {
+		return rawURL
+	}
+
+	query, fragment, hasFragment := strings.Cut(query, "#")
+	params := strings.Split(query, "&")
+
+	for i, param := range params {
+		name, _, hasValue := strings.Cut(param, "=")
+		if hasValue && masker.params.MatchString(name) {
+			params[i] = name + "=" + RedactedValue
+		}
+	}
+
+	result := base + "?" + strings.Join(params, "&")
+	if hasFragment {
+		result += "#" + fragment
+	}
+
+	return result
+}
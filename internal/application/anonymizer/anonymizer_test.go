@@ -0,0 +1,88 @@
+package anonymizer
+
+import (
+	"regexp"
+	"testing"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestLogRecords() []domain.LogRecord {
+	return []domain.LogRecord{
+		{
+			RemoteAddr:   "203.0.113.77",
+			PeerAddr:     "10.0.0.1",
+			ForwardedFor: "203.0.113.77, 10.0.0.2",
+			RemoteUser:   "alice",
+			URL:          "/reset?email=alice@example.com&step=2",
+			Referer:      "https://mail.example.com/inbox?token=secret#top",
+		},
+		{
+			RemoteAddr: "2001:db8:1234:5678::1",
+			RemoteUser: "-",
+			URL:        "/",
+		},
+		{
+			RemoteAddr: "203.0.113.77",
+			RemoteUser: "alice",
+			URL:        "/profile",
+		},
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	anonymizer := NewLogAnonymizer()
+
+	t.Run("Disabled", func(t *testing.T) {
+		records, err := anonymizer.Anonymize(createTestLogRecords(), &domain.Config{})
+
+		require.NoError(t, err)
+		assert.Equal(t, createTestLogRecords(), records)
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		config := &domain.Config{Anonymize: domain.AnonymizeOptions{Mode: domain.AnonymizeTruncate}}
+
+		records, err := anonymizer.Anonymize(createTestLogRecords(), config)
+		require.NoError(t, err)
+
+		assert.Equal(t, "203.0.113.0", records[0].RemoteAddr)
+		assert.Equal(t, "10.0.0.0", records[0].PeerAddr)
+		assert.Equal(t, "203.0.113.0, 10.0.0.0", records[0].ForwardedFor)
+		assert.Equal(t, "2001:db8:1234::", records[1].RemoteAddr)
+		assert.Regexp(t, "^user-[0-9a-f]{12}$", records[0].RemoteUser)
+		assert.Equal(t, records[0].RemoteUser, records[2].RemoteUser)
+		assert.Equal(t, "-", records[1].RemoteUser)
+		assert.Equal(t, "/reset?email=alice@example.com&step=2", records[0].URL)
+	})
+
+	t.Run("HMAC", func(t *testing.T) {
+		config := &domain.Config{Anonymize: domain.AnonymizeOptions{Mode: domain.AnonymizeHMAC, Key: []byte("secret")}}
+
+		first, err := anonymizer.Anonymize(createTestLogRecords(), config)
+		require.NoError(t, err)
+
+		second, err := anonymizer.Anonymize(createTestLogRecords(), config)
+		require.NoError(t, err)
+
+		assert.Regexp(t, "^ip-[0-9a-f]{12}$", first[0].RemoteAddr)
+		assert.Equal(t, first[0].RemoteAddr, first[2].RemoteAddr)
+		assert.NotEqual(t, first[0].RemoteAddr, first[1].RemoteAddr)
+		assert.Equal(t, first, second, "С одним ключом псевдонимы должны совпадать")
+	})
+
+	t.Run("RedactParams", func(t *testing.T) {
+		config := &domain.Config{Anonymize: domain.AnonymizeOptions{RedactParams: regexp.MustCompile("^(token|email)$")}}
+
+		records, err := anonymizer.Anonymize(createTestLogRecords(), config)
+		require.NoError(t, err)
+
+		assert.Equal(t, "/reset?email=REDACTED&step=2", records[0].URL)
+		assert.Equal(t, "https://mail.example.com/inbox?token=REDACTED#top", records[0].Referer)
+		assert.Equal(t, "203.0.113.77", records[0].RemoteAddr)
+		assert.Equal(t, "/", records[1].URL)
+	})
+}
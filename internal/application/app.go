@@ -2,23 +2,77 @@ package application
 
 import (
 	domain "analyzer/internal/domain"
+	"fmt"
+	"io"
 	"log"
+	"time"
 )
 
 type ParserLog interface {
 	Parse(config *domain.Config) ([]domain.LogRecord, error)
+	ParseReader(reader io.Reader, config *domain.Config) ([]domain.LogRecord, error)
+	ParseLines(logs []string) ([]domain.LogRecord, []error)
+	ParseBatches(config *domain.Config, handle func(records []domain.LogRecord) error) error
+	ParseIncremental(config *domain.Config,
+		files []domain.FileCheckpoint) ([]domain.LogRecord, []domain.FileCheckpoint, []error, error)
+}
+
+type NormalizerURL interface {
+	Normalize(records []domain.LogRecord, config *domain.Config) []domain.LogRecord
+}
+
+type EnricherLog interface {
+	Enrich(records []domain.LogRecord, config *domain.Config) ([]domain.LogRecord, error)
+	Close() error
 }
 
 type FilterLog interface {
 	Filter(records []domain.LogRecord, config *domain.Config) []domain.LogRecord
 }
 
+type AnonymizerLog interface {
+	Anonymize(records []domain.LogRecord, config *domain.Config) ([]domain.LogRecord, error)
+}
+
 type LogAnalyzer interface {
 	Analyze(records []domain.LogRecord, config *domain.Config) (domain.LogReport, error)
+	AnalyzeBatches(batches domain.RecordBatches, config *domain.Config) (domain.LogReport, error)
+	State(records []domain.LogRecord, config *domain.Config) (domain.AnalyzerState, error)
+	StateBatches(batches domain.RecordBatches, config *domain.Config) (domain.AnalyzerState, error)
+	MergeStates(states []domain.AnalyzerState, config *domain.Config) (domain.AnalyzerState, error)
+	AnalyzeState(state *domain.AnalyzerState, config *domain.Config) (domain.LogReport, error)
+	Rollups(records []domain.LogRecord) []domain.Rollup
+	AnalyzeRollups(rollups []domain.Rollup, config *domain.Config) (domain.LogReport, error)
+}
+
+type Exporter interface {
+	Export(records []domain.LogRecord, options domain.ExportOptions) error
+}
+
+type RollupStore interface {
+	Ingest(path string, rollups []domain.Rollup, files []domain.FileCheckpoint, retention domain.RetentionOptions) error
+	Sources(path string) ([]domain.FileCheckpoint, error)
+	Query(path string, from, to time.Time) ([]domain.Rollup, error)
+}
+
+type StateStore interface {
+	Load(path string) (domain.AnalyzerState, error)
+	Save(state *domain.AnalyzerState, path string) error
+	LoadCheckpoint(path string) (domain.Checkpoint, error)
+	SaveCheckpoint(checkpoint *domain.Checkpoint, path string) error
+}
+
+type ReportLoader interface {
+	Load(path string) (domain.LogReport, error)
+}
+
+type ReportDiffer interface {
+	Compare(base, current *domain.LogReport) domain.ReportDiff
 }
 
 type Formatter interface {
 	Format(report *domain.LogReport, format string) (string, error)
+	FormatDiff(reportDiff *domain.ReportDiff, format string) (string, error)
 }
 
 type Saver interface {
@@ -26,22 +80,73 @@ type Saver interface {
 }
 
 type AnalyzerApp struct {
-	LogParser   ParserLog
-	LogFilter   FilterLog
-	LogAnalyzer LogAnalyzer
-	Formatter   Formatter
-	Saver       Saver
+	LogParser     ParserLog
+	URLNormalizer NormalizerURL
+	LogEnricher   EnricherLog
+	LogFilter     FilterLog
+	LogAnonymizer AnonymizerLog
+	LogAnalyzer   LogAnalyzer
+	ReportLoader  ReportLoader
+	StateStore    StateStore
+	RollupStore   RollupStore
+	Exporter      Exporter
+	ReportDiffer  ReportDiffer
+	Formatter     Formatter
+	Saver         Saver
+}
+
+func (app *AnalyzerApp) Close() error {
+	return app.LogEnricher.Close()
 }
 
 func (app *AnalyzerApp) Run(config *domain.Config) {
-	logRecords, err := app.LogParser.Parse(config)
+	if config.EmitState != "" {
+		app.RunEmitState(config)
+		return
+	}
+
+	logReport, err := app.Report(config)
 	if err != nil {
 		log.Fatalf("Ошибка: %v", err)
 	}
 
-	logRecords = app.LogFilter.Filter(logRecords, config)
+	output, err := app.Formatter.Format(&logReport, config.Format)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
 
-	logReport, err := app.LogAnalyzer.Analyze(logRecords, config)
+	err = app.Saver.Save(output, "analyze", config.Format)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
+
+func (app *AnalyzerApp) RunEmitState(config *domain.Config) {
+	state, err := app.State(config)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+
+	err = app.StateStore.Save(&state, config.EmitState)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
+
+func (app *AnalyzerApp) RunIngest(config *domain.Config) {
+	err := app.Ingest(config)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
+
+func (app *AnalyzerApp) RunQuery(config *domain.Config) {
+	rollups, err := app.RollupStore.Query(config.Store, config.From, config.To)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+
+	logReport, err := app.LogAnalyzer.AnalyzeRollups(rollups, config)
 	if err != nil {
 		log.Fatalf("Ошибка: %v", err)
 	}
@@ -51,8 +156,299 @@ func (app *AnalyzerApp) Run(config *domain.Config) {
 		log.Fatalf("Ошибка: %v", err)
 	}
 
-	err = app.Saver.Save(output, "analyze", config.Format)
+	err = app.Saver.Save(output, "query", config.Format)
 	if err != nil {
 		log.Fatalf("Ошибка: %v", err)
 	}
 }
+
+func (app *AnalyzerApp) RunExport(config *domain.Config) {
+	logRecords, err := app.LogParser.Parse(config)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+
+	logRecords, err = app.prepare(logRecords, config)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+
+	err = app.Exporter.Export(logRecords, config.Export)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
+
+func (app *AnalyzerApp) RunDiff(base, current *domain.Config) {
+	baseReport, err := app.Report(base)
+	if err != nil {
+		log.Fatalf("Ошибка в базовом отчёте: %v", err)
+	}
+
+	currentReport, err := app.Report(current)
+	if err != nil {
+		log.Fatalf("Ошибка в текущем отчёте: %v", err)
+	}
+
+	reportDiff := app.ReportDiffer.Compare(&baseReport, &currentReport)
+
+	output, err := app.Formatter.FormatDiff(&reportDiff, current.Format)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+
+	err = app.Saver.Save(output, "diff", current.Format)
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+}
+
+func (app *AnalyzerApp) Report(config *domain.Config) (domain.LogReport, error) {
+	if config.TypePath == "report" {
+		return app.ReportLoader.Load(config.Path)
+	}
+
+	if config.TypePath == "state" || config.Checkpoint != "" {
+		state, err := app.State(config)
+		if err != nil {
+			return domain.LogReport{}, err
+		}
+
+		return app.LogAnalyzer.AnalyzeState(&state, config)
+	}
+
+	if config.Approx > 0 {
+		return app.LogAnalyzer.AnalyzeBatches(app.batches(config), config)
+	}
+
+	logRecords, err := app.LogParser.Parse(config)
+	if err != nil {
+		return domain.LogReport{}, err
+	}
+
+	logRecords, err = app.prepare(logRecords, config)
+	if err != nil {
+		return domain.LogReport{}, err
+	}
+
+	return app.LogAnalyzer.Analyze(logRecords, config)
+}
+
+func (app *AnalyzerApp) State(config *domain.Config) (domain.AnalyzerState, error) {
+	if config.TypePath == "state" {
+		states := make([]domain.AnalyzerState, 0, len(config.States))
+
+		for _, path := range config.States {
+			state, err := app.StateStore.Load(path)
+			if err != nil {
+				return domain.AnalyzerState{}, err
+			}
+
+			states = append(states, state)
+		}
+
+		return app.LogAnalyzer.MergeStates(states, config)
+	}
+
+	if config.Checkpoint != "" {
+		return app.checkpointState(config)
+	}
+
+	if config.Approx > 0 {
+		return app.LogAnalyzer.StateBatches(app.batches(config), config)
+	}
+
+	logRecords, err := app.LogParser.Parse(config)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	logRecords, err = app.prepare(logRecords, config)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	return app.LogAnalyzer.State(logRecords, config)
+}
+
+func (app *AnalyzerApp) checkpointState(config *domain.Config) (domain.AnalyzerState, error) {
+	checkpoint, err := app.StateStore.LoadCheckpoint(config.Checkpoint)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	logRecords, files, errs, err := app.LogParser.ParseIncremental(config, checkpoint.Files)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	LogRejected(errs)
+
+	logRecords, err = app.prepare(logRecords, config)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	state, err := app.LogAnalyzer.State(logRecords, config)
+	if err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	if checkpoint.State != nil {
+		fileNames := state.FileNames
+
+		state, err = app.LogAnalyzer.MergeStates([]domain.AnalyzerState{*checkpoint.State, state}, config)
+		if err != nil {
+			return domain.AnalyzerState{}, fmt.Errorf("контрольная точка %s несовместима с текущими настройками: %v",
+				config.Checkpoint, err)
+		}
+
+		state.FileNames = fileNames
+	}
+
+	checkpoint.Files = files
+	checkpoint.State = &state
+
+	if err := app.StateStore.SaveCheckpoint(&checkpoint, config.Checkpoint); err != nil {
+		return domain.AnalyzerState{}, err
+	}
+
+	return state, nil
+}
+
+func (app *AnalyzerApp) Ingest(config *domain.Config) error {
+	if config.TypePath != "local" {
+		return fmt.Errorf("ingest поддерживает только локальные файлы: для них хранилище запоминает прочитанные позиции")
+	}
+
+	checkpoint := domain.Checkpoint{Version: domain.CheckpointVersion}
+
+	var err error
+
+	if config.Checkpoint != "" {
+		checkpoint, err = app.StateStore.LoadCheckpoint(config.Checkpoint)
+	} else {
+		checkpoint.Files, err = app.RollupStore.Sources(config.Store)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logRecords, files, errs, err := app.LogParser.ParseIncremental(config, checkpoint.Files)
+	if err != nil {
+		return err
+	}
+
+	LogRejected(errs)
+
+	logRecords, err = app.prepare(logRecords, config)
+	if err != nil {
+		return err
+	}
+
+	err = app.RollupStore.Ingest(config.Store, app.LogAnalyzer.Rollups(logRecords), files, config.Retention)
+	if err != nil {
+		return err
+	}
+
+	if config.Checkpoint != "" {
+		checkpoint.Files = files
+		return app.StateStore.SaveCheckpoint(&checkpoint, config.Checkpoint)
+	}
+
+	return nil
+}
+
+func (app *AnalyzerApp) Records(config *domain.Config) ([]domain.LogRecord, error) {
+	logRecords, err := app.LogParser.Parse(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.enrich(logRecords, config)
+}
+
+func (app *AnalyzerApp) RecordsIncremental(config *domain.Config,
+	files []domain.FileCheckpoint) ([]domain.LogRecord, []domain.FileCheckpoint, []error, error) {
+	logRecords, files, errs, err := app.LogParser.ParseIncremental(config, files)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	logRecords, err = app.enrich(logRecords, config)
+	if err != nil {
+		return nil, files, errs, err
+	}
+
+	return logRecords, files, errs, nil
+}
+
+func (app *AnalyzerApp) ReadRecords(reader io.Reader, config *domain.Config) ([]domain.LogRecord, error) {
+	logRecords, err := app.LogParser.ParseReader(reader, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.enrich(logRecords, config)
+}
+
+func (app *AnalyzerApp) ReceiveRecords(lines []string, config *domain.Config) ([]domain.LogRecord, []error, error) {
+	logRecords, errs := app.LogParser.ParseLines(lines)
+
+	logRecords, err := app.enrich(logRecords, config)
+	if err != nil {
+		return nil, errs, err
+	}
+
+	return logRecords, errs, nil
+}
+
+func LogRejected(errs []error) {
+	if len(errs) > 0 {
+		log.Printf("Пропущено строк лога: %d, первая ошибка: %v", len(errs), errs[0])
+	}
+}
+
+func (app *AnalyzerApp) AnalyzeRecords(logRecords []domain.LogRecord, config *domain.Config) (domain.LogReport, error) {
+	logRecords, err := app.SelectRecords(logRecords, config)
+	if err != nil {
+		return domain.LogReport{}, err
+	}
+
+	return app.LogAnalyzer.Analyze(logRecords, config)
+}
+
+func (app *AnalyzerApp) batches(config *domain.Config) domain.RecordBatches {
+	return func(handle func(records []domain.LogRecord) error) error {
+		return app.LogParser.ParseBatches(config, func(logRecords []domain.LogRecord) error {
+			logRecords, err := app.prepare(logRecords, config)
+			if err != nil {
+				return err
+			}
+
+			return handle(logRecords)
+		})
+	}
+}
+
+func (app *AnalyzerApp) prepare(logRecords []domain.LogRecord, config *domain.Config) ([]domain.LogRecord, error) {
+	logRecords, err := app.enrich(logRecords, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.SelectRecords(logRecords, config)
+}
+
+func (app *AnalyzerApp) enrich(logRecords []domain.LogRecord, config *domain.Config) ([]domain.LogRecord, error) {
+	logRecords = app.URLNormalizer.Normalize(logRecords, config)
+
+	return app.LogEnricher.Enrich(logRecords, config)
+}
+
+func (app *AnalyzerApp) SelectRecords(logRecords []domain.LogRecord, config *domain.Config) ([]domain.LogRecord, error) {
+	logRecords = app.LogFilter.Filter(logRecords, config)
+
+	return app.LogAnonymizer.Anonymize(logRecords, config)
+}
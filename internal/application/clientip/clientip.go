@@ -0,0 +1,98 @@
+package clientip
+
+import (
+	domain "analyzer/internal/domain"
+	"net/netip"
+	"strings"
+)
+
+type Resolver struct {
+	mode    string
+	trusted []netip.Prefix
+}
+
+func NewResolver(options domain.ClientIPOptions) *Resolver {
+	return &Resolver{
+		mode:    options.Mode,
+		trusted: options.TrustedProxies,
+	}
+}
+
+func (resolver *Resolver) Resolve(record *domain.LogRecord) {
+	if resolver.mode == "" || resolver.mode == domain.ClientIPRemote {
+		return
+	}
+
+	if !resolver.isTrusted(record.RemoteAddr) {
+		return
+	}
+
+	var client string
+
+	switch resolver.mode {
+	case domain.ClientIPForwarded:
+		client = resolver.fromForwardedFor(record.ForwardedFor)
+	case domain.ClientIPRealIP:
+		if addr, ok := parseAddr(record.RealIP); ok {
+			client = addr.String()
+		}
+	}
+
+	if client == "" {
+		return
+	}
+
+	record.PeerAddr = record.RemoteAddr
+	record.RemoteAddr = client
+}
+
+func (resolver *Resolver) fromForwardedFor(header string) string {
+	hops := strings.Split(header, ",")
+
+	client := ""
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(hops[i])
+		if !ok {
+			break
+		}
+
+		client = addr.String()
+
+		if !resolver.isTrustedAddr(addr) {
+			break
+		}
+	}
+
+	return client
+}
+
+func (resolver *Resolver) isTrusted(value string) bool {
+	addr, ok := parseAddr(value)
+
+	return ok && resolver.isTrustedAddr(addr)
+}
+
+func (resolver *Resolver) isTrustedAddr(addr netip.Addr) bool {
+	for _, prefix := range resolver.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseAddr(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return addr.Unmap(), true
+	}
+
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	return netip.Addr{}, false
+}
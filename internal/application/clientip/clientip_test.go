@@ -0,0 +1,54 @@
+package clientip
+
+import (
+	"net/netip"
+	"testing"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8::/32")}
+
+	cases := []struct {
+		name     string
+		mode     string
+		record   domain.LogRecord
+		expected string
+	}{
+		{"RemoteMode", domain.ClientIPRemote,
+			domain.LogRecord{RemoteAddr: "10.0.0.1", ForwardedFor: "203.0.113.7"}, "10.0.0.1"},
+		{"SingleHop", domain.ClientIPForwarded,
+			domain.LogRecord{RemoteAddr: "10.0.0.1", ForwardedFor: "203.0.113.7"}, "203.0.113.7"},
+		{"SkipsTrustedHops", domain.ClientIPForwarded,
+			domain.LogRecord{RemoteAddr: "10.0.0.1", ForwardedFor: "198.51.100.1, 203.0.113.7, 10.1.2.3"}, "203.0.113.7"},
+		{"HopWithPort", domain.ClientIPForwarded,
+			domain.LogRecord{RemoteAddr: "10.0.0.1", ForwardedFor: "203.0.113.7:51234"}, "203.0.113.7"},
+		{"UntrustedPeer", domain.ClientIPForwarded,
+			domain.LogRecord{RemoteAddr: "198.51.100.9", ForwardedFor: "203.0.113.7"}, "198.51.100.9"},
+		{"EmptyHeader", domain.ClientIPForwarded,
+			domain.LogRecord{RemoteAddr: "10.0.0.1"}, "10.0.0.1"},
+		{"RealIP", domain.ClientIPRealIP,
+			domain.LogRecord{RemoteAddr: "2001:db8::1", RealIP: "2001:db9::42"}, "2001:db9::42"},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			resolver := NewResolver(domain.ClientIPOptions{Mode: testCase.mode, TrustedProxies: trusted})
+			record := testCase.record
+			peer := record.RemoteAddr
+
+			resolver.Resolve(&record)
+
+			assert.Equal(t, testCase.expected, record.RemoteAddr)
+
+			if testCase.expected != peer {
+				assert.Equal(t, peer, record.PeerAddr)
+			} else {
+				assert.Empty(t, record.PeerAddr)
+			}
+		})
+	}
+}
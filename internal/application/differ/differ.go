@@ -0,0 +1,224 @@
+package differ
+
+import (
+	domain "analyzer/internal/domain"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	topResourceLimit    = 10
+	errorRateThreshold  = 1.0
+	trafficDropPercent  = -30.0
+	responseGrowPercent = 50.0
+)
+
+type metricSpec struct {
+	name    string
+	kind    string
+	value   func(report *domain.LogReport) float64
+	regress func(delta domain.MetricDelta) bool
+}
+
+type ReportDiffer struct {
+	metrics []metricSpec
+}
+
+func NewReportDiffer() *ReportDiffer {
+	return &ReportDiffer{
+		metrics: []metricSpec{
+			{name: "Количество запросов", kind: domain.MetricCount, value: totalRequests, regress: trafficDropped},
+			{name: "Средний размер ответа", kind: domain.MetricBytes, value: avgBodySize, regress: responseGrew},
+			{name: "95p размера ответа", kind: domain.MetricBytes, value: percentile95Size, regress: responseGrew},
+			{name: "Среднее время между запросами", kind: domain.MetricDuration, value: avgTimeBetweenRequests},
+			{name: "Доля ошибок 4xx", kind: domain.MetricPercent, value: clientErrorRate, regress: errorRateGrew},
+			{name: "Доля ошибок 5xx", kind: domain.MetricPercent, value: serverErrorRate, regress: errorRateGrew},
+			{name: "Всего передано", kind: domain.MetricBytes, value: totalBytes},
+			{name: "Доля ботов", kind: domain.MetricPercent, value: botShare},
+		},
+	}
+}
+
+func (differ *ReportDiffer) Compare(base, current *domain.LogReport) domain.ReportDiff {
+	reportDiff := domain.ReportDiff{
+		Base:    reportSource(base),
+		Current: reportSource(current),
+	}
+
+	for _, metric := range differ.metrics {
+		delta := newMetricDelta(metric.name, metric.kind, metric.value(base), metric.value(current))
+		delta.Regression = metric.regress != nil && metric.regress(delta)
+
+		if delta.Regression {
+			reportDiff.Regressions = append(reportDiff.Regressions, describeRegression(&delta))
+		}
+
+		reportDiff.Metrics = append(reportDiff.Metrics, delta)
+	}
+
+	differ.compareStatusCodes(base, current, &reportDiff)
+	differ.compareResources(base, current, &reportDiff)
+
+	return reportDiff
+}
+
+func (differ *ReportDiffer) compareStatusCodes(base, current *domain.LogReport, reportDiff *domain.ReportDiff) {
+	codes := make([]int, 0, len(base.ResponseCodes)+len(current.ResponseCodes))
+
+	for code := range base.ResponseCodes {
+		codes = append(codes, code)
+	}
+
+	for code := range current.ResponseCodes {
+		if _, exists := base.ResponseCodes[code]; !exists {
+			codes = append(codes, code)
+		}
+	}
+
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		baseCode, inBase := base.ResponseCodes[code]
+		currentCode, inCurrent := current.ResponseCodes[code]
+
+		name := statusName(code, baseCode.Name, currentCode.Name)
+		reportDiff.StatusCodes = append(reportDiff.StatusCodes,
+			newMetricDelta(name, domain.MetricCount, float64(baseCode.Count), float64(currentCode.Count)))
+
+		switch {
+		case !inBase:
+			reportDiff.NewStatusCodes = append(reportDiff.NewStatusCodes, domain.ValueCount{Value: name, Count: currentCode.Count})
+
+			if code >= 500 {
+				reportDiff.Regressions = append(reportDiff.Regressions,
+					fmt.Sprintf("Появился код ответа %s (%d запросов)", name, currentCode.Count))
+			}
+		case !inCurrent:
+			reportDiff.GoneStatusCodes = append(reportDiff.GoneStatusCodes, domain.ValueCount{Value: name, Count: baseCode.Count})
+		}
+	}
+}
+
+func (differ *ReportDiffer) compareResources(base, current *domain.LogReport, reportDiff *domain.ReportDiff) {
+	baseTop := topResources(base)
+	currentTop := topResources(current)
+
+	for _, resource := range currentTop {
+		if !slices.Contains(baseTop, resource) {
+			reportDiff.NewResources = append(reportDiff.NewResources,
+				domain.ValueCount{Value: resource, Count: current.RequestedResources[resource]})
+		}
+	}
+
+	for _, resource := range baseTop {
+		if !slices.Contains(currentTop, resource) {
+			reportDiff.GoneResources = append(reportDiff.GoneResources,
+				domain.ValueCount{Value: resource, Count: base.RequestedResources[resource]})
+		}
+	}
+}
+
+func newMetricDelta(name, kind string, base, current float64) domain.MetricDelta {
+	delta := domain.MetricDelta{
+		Name:    name,
+		Kind:    kind,
+		Base:    base,
+		Current: current,
+		Delta:   current - base,
+	}
+
+	if base != 0 {
+		change := (current - base) / base * 100
+		delta.ChangePercent = &change
+	}
+
+	return delta
+}
+
+func describeRegression(delta *domain.MetricDelta) string {
+	switch delta.Kind {
+	case domain.MetricPercent:
+		return fmt.Sprintf("%s выросла с %.2f%% до %.2f%%", delta.Name, delta.Base, delta.Current)
+	case domain.MetricCount:
+		return fmt.Sprintf("%s снизилось на %.2f%%", delta.Name, -*delta.ChangePercent)
+	default:
+		return fmt.Sprintf("%s вырос на %.2f%%", delta.Name, *delta.ChangePercent)
+	}
+}
+
+func reportSource(report *domain.LogReport) domain.ReportSource {
+	return domain.ReportSource{
+		FileNames:     report.FileNames,
+		URLName:       report.URLName,
+		TotalRequests: report.TotalRequests,
+	}
+}
+
+func topResources(report *domain.LogReport) []string {
+	return report.SortedRequestedResources[:min(topResourceLimit, len(report.SortedRequestedResources))]
+}
+
+func statusName(code int, names ...string) string {
+	for _, name := range names {
+		if name != "" {
+			return strconv.Itoa(code) + " " + name
+		}
+	}
+
+	return strconv.Itoa(code)
+}
+
+func totalRequests(report *domain.LogReport) float64 {
+	return float64(report.TotalRequests)
+}
+
+func avgBodySize(report *domain.LogReport) float64 {
+	return float64(report.AvgBodySize)
+}
+
+func percentile95Size(report *domain.LogReport) float64 {
+	return float64(report.Percentile95Size)
+}
+
+func avgTimeBetweenRequests(report *domain.LogReport) float64 {
+	return float64(report.AvgTimeBetweenRequests) / float64(time.Second)
+}
+
+func clientErrorRate(report *domain.LogReport) float64 {
+	return rate(report.ClientErrors, report.TotalRequests)
+}
+
+func serverErrorRate(report *domain.LogReport) float64 {
+	return rate(report.ServerErrors, report.TotalRequests)
+}
+
+func totalBytes(report *domain.LogReport) float64 {
+	return float64(report.Traffic.TotalBytes)
+}
+
+func botShare(report *domain.LogReport) float64 {
+	return rate(report.UserAgents.BotRequests, report.UserAgents.BotRequests+report.UserAgents.HumanRequests)
+}
+
+func rate(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) * 100 / float64(total)
+}
+
+func trafficDropped(delta domain.MetricDelta) bool {
+	return delta.ChangePercent != nil && *delta.ChangePercent <= trafficDropPercent
+}
+
+func responseGrew(delta domain.MetricDelta) bool {
+	return delta.ChangePercent != nil && *delta.ChangePercent >= responseGrowPercent
+}
+
+func errorRateGrew(delta domain.MetricDelta) bool {
+	return delta.Delta >= errorRateThreshold
+}
@@ -0,0 +1,105 @@
+package differ
+
+import (
+	"testing"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestReports() (base, current domain.LogReport) {
+	base = domain.LogReport{
+		FileNames:     []string{"last-week.log"},
+		TotalRequests: 1000,
+		AvgBodySize:   500,
+		ClientErrors:  50,
+		ServerErrors:  5,
+		ResponseCodes: map[int]domain.ResponseCode{
+			200: {Name: "OK", Count: 945},
+			404: {Name: "Not Found", Count: 50},
+			500: {Name: "Internal Server Error", Count: 5},
+		},
+		RequestedResources:       map[string]int{"/": 600, "/about": 300, "/old": 100},
+		SortedRequestedResources: []string{"/", "/about", "/old"},
+	}
+
+	current = domain.LogReport{
+		FileNames:     []string{"today.log"},
+		TotalRequests: 600,
+		AvgBodySize:   1000,
+		ClientErrors:  30,
+		ServerErrors:  60,
+		ResponseCodes: map[int]domain.ResponseCode{
+			200: {Name: "OK", Count: 510},
+			404: {Name: "Not Found", Count: 30},
+			503: {Name: "Service Unavailable", Count: 60},
+		},
+		RequestedResources:       map[string]int{"/": 400, "/new": 150, "/about": 50},
+		SortedRequestedResources: []string{"/", "/new", "/about"},
+	}
+
+	return base, current
+}
+
+func findMetric(t *testing.T, metrics []domain.MetricDelta, name string) domain.MetricDelta {
+	t.Helper()
+
+	for _, metric := range metrics {
+		if metric.Name == name {
+			return metric
+		}
+	}
+
+	require.Failf(t, "метрика не найдена", "%s", name)
+
+	return domain.MetricDelta{}
+}
+
+func TestCompare(t *testing.T) {
+	differ := NewReportDiffer()
+	base, current := createTestReports()
+
+	reportDiff := differ.Compare(&base, &current)
+
+	t.Run("Totals", func(t *testing.T) {
+		total := findMetric(t, reportDiff.Metrics, "Количество запросов")
+
+		assert.Equal(t, float64(1000), total.Base)
+		assert.Equal(t, float64(600), total.Current)
+		assert.Equal(t, float64(-400), total.Delta)
+		require.NotNil(t, total.ChangePercent)
+		assert.InDelta(t, -40.0, *total.ChangePercent, 0.001)
+		assert.True(t, total.Regression, "Падение трафика на 40% должно считаться регрессией")
+	})
+
+	t.Run("ErrorRates", func(t *testing.T) {
+		clientErrors := findMetric(t, reportDiff.Metrics, "Доля ошибок 4xx")
+		serverErrors := findMetric(t, reportDiff.Metrics, "Доля ошибок 5xx")
+
+		assert.InDelta(t, 5.0, clientErrors.Base, 0.001)
+		assert.InDelta(t, 5.0, clientErrors.Current, 0.001)
+		assert.False(t, clientErrors.Regression)
+		assert.InDelta(t, 0.5, serverErrors.Base, 0.001)
+		assert.InDelta(t, 10.0, serverErrors.Current, 0.001)
+		assert.True(t, serverErrors.Regression)
+	})
+
+	t.Run("StatusCodes", func(t *testing.T) {
+		assert.Equal(t, []domain.ValueCount{{Value: "503 Service Unavailable", Count: 60}}, reportDiff.NewStatusCodes)
+		assert.Equal(t, []domain.ValueCount{{Value: "500 Internal Server Error", Count: 5}}, reportDiff.GoneStatusCodes)
+		assert.Len(t, reportDiff.StatusCodes, 4)
+	})
+
+	t.Run("Resources", func(t *testing.T) {
+		assert.Equal(t, []domain.ValueCount{{Value: "/new", Count: 150}}, reportDiff.NewResources)
+		assert.Equal(t, []domain.ValueCount{{Value: "/old", Count: 100}}, reportDiff.GoneResources)
+	})
+
+	t.Run("Regressions", func(t *testing.T) {
+		assert.Contains(t, reportDiff.Regressions, "Доля ошибок 5xx выросла с 0.50% до 10.00%")
+		assert.Contains(t, reportDiff.Regressions, "Появился код ответа 503 Service Unavailable (60 запросов)")
+		assert.Contains(t, reportDiff.Regressions, "Средний размер ответа вырос на 100.00%")
+	})
+}
@@ -0,0 +1,96 @@
+package enricher
+
+import (
+	clientip "analyzer/internal/application/clientip"
+	geoip "analyzer/internal/application/geoip"
+	useragent "analyzer/internal/application/useragent"
+	domain "analyzer/internal/domain"
+	"slices"
+	"sync"
+)
+
+type LogEnricher struct {
+	mutex    sync.Mutex
+	geoPaths []string
+	geo      *geoip.Resolver
+}
+
+func NewLogEnricher() *LogEnricher {
+	return &LogEnricher{}
+}
+
+func (enricher *LogEnricher) Enrich(records []domain.LogRecord, config *domain.Config) ([]domain.LogRecord, error) {
+	classifier, err := enricher.getClassifier(config)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := clientip.NewResolver(config.ClientIP)
+
+	for ind := range records {
+		record := &records[ind]
+		clients.Resolve(record)
+		record.Agent = classifier.Classify(record.UserAgent)
+	}
+
+	if len(config.GeoIPDatabases) == 0 {
+		return records, nil
+	}
+
+	resolver, err := enricher.getResolver(config.GeoIPDatabases)
+	if err != nil {
+		return nil, err
+	}
+
+	for ind := range records {
+		record := &records[ind]
+		record.Geo = resolver.Lookup(record.RemoteAddr)
+	}
+
+	return records, nil
+}
+
+func (enricher *LogEnricher) Close() error {
+	enricher.mutex.Lock()
+	defer enricher.mutex.Unlock()
+
+	if enricher.geo == nil {
+		return nil
+	}
+
+	err := enricher.geo.Close()
+	enricher.geo = nil
+
+	return err
+}
+
+func (enricher *LogEnricher) getResolver(paths []string) (*geoip.Resolver, error) {
+	enricher.mutex.Lock()
+	defer enricher.mutex.Unlock()
+
+	if enricher.geo != nil && slices.Equal(enricher.geoPaths, paths) {
+		return enricher.geo, nil
+	}
+
+	resolver, err := geoip.Open(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if enricher.geo != nil {
+		_ = enricher.geo.Close()
+	}
+
+	enricher.geoPaths = paths
+	enricher.geo = resolver
+
+	return resolver, nil
+}
+
+func (enricher *LogEnricher) getClassifier(config *domain.Config) (*useragent.Classifier, error) {
+	if config.UserAgentRules == "" {
+		return useragent.DefaultClassifier(), nil
+	}
+
+	return useragent.LoadClassifier(config.UserAgentRules)
+}
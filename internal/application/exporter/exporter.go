@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	domain "analyzer/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const defaultBatchSize = 1000
+
+type Exporter struct {
+	batchSize int
+}
+
+func NewExporter() *Exporter {
+	return &Exporter{batchSize: defaultBatchSize}
+}
+
+func (exporter *Exporter) Export(records []domain.LogRecord, options domain.ExportOptions) error {
+	if err := prepareOutput(options); err != nil {
+		return err
+	}
+
+	switch options.Format {
+	case domain.ExportSQLite:
+		return exporter.exportSQLite(records, options.Output)
+	case domain.ExportParquet:
+		return exporter.exportParquet(records, options)
+	default:
+		return fmt.Errorf("неподдерживаемый формат экспорта: %s", options.Format)
+	}
+}
+
+func prepareOutput(options domain.ExportOptions) error {
+	info, err := os.Stat(options.Output)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	switch options.Mode {
+	case domain.ExportAppend:
+		if options.Format == domain.ExportParquet && options.Partition == "" {
+			return fmt.Errorf("дописать записи в файл parquet %s нельзя, используйте --partition date или --mode replace", options.Output)
+		}
+
+		return nil
+	case domain.ExportReplace:
+		if err == nil && info.IsDir() {
+			return removePartitions(options.Output)
+		}
+
+		if err := os.Remove(options.Output); err != nil {
+			return fmt.Errorf("не удалось заменить файл %s: %v", options.Output, err)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("файл %s уже существует, используйте --mode append или --mode replace", options.Output)
+	}
+}
+
+func removePartitions(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать каталог %s: %v", dir, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), partitionPrefix) {
+			return fmt.Errorf("каталог %s содержит не только разделы экспорта, удалите его вручную", dir)
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("не удалось заменить каталог %s: %v", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package exporter
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRecords() []domain.LogRecord {
+	record := domain.LogRecord{
+		RemoteAddr:      "192.168.1.1",
+		RemoteUser:      "-",
+		TimeLocal:       time.Date(2024, 8, 31, 10, 0, 0, 0, time.UTC),
+		Method:          "GET",
+		URL:             "/index.html",
+		ProtocolVersion: "HTTP/1.1",
+		Status:          200,
+		BodyBytesSent:   512,
+		Referer:         "-",
+		UserAgent:       "Mozilla/5.0",
+		Agent:           domain.UserAgent{Browser: "Firefox"},
+	}
+
+	failed := record
+	failed.URL = "/missing"
+	failed.Status = 404
+
+	return []domain.LogRecord{record, record, failed}
+}
+
+func countRows(t *testing.T, path, query string, args ...any) int {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var count int
+
+	require.NoError(t, db.QueryRow(query, args...).Scan(&count))
+
+	return count
+}
+
+func TestExporter_SQLite(t *testing.T) {
+	exporter := &Exporter{batchSize: 2}
+	records := createRecords()
+	path := filepath.Join(t.TempDir(), "out.db")
+	options := domain.ExportOptions{Format: domain.ExportSQLite, Output: path, Mode: domain.ExportCreate}
+
+	require.NoError(t, exporter.Export(records, options))
+
+	assert.Equal(t, 3, countRows(t, path, "SELECT COUNT(*) FROM records"))
+	assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM records WHERE status = ? AND url = ?", 404, "/missing"))
+	assert.Equal(t, 3, countRows(t, path, "SELECT COUNT(*) FROM records WHERE time >= ? AND browser = ?",
+		"2024-08-31 00:00:00", "Firefox"))
+	assert.Equal(t, 3, countRows(t, path, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name LIKE 'records_%'"))
+
+	t.Run("ExistingFile", func(t *testing.T) {
+		assert.Error(t, exporter.Export(records, options))
+	})
+
+	t.Run("AppendIdempotent", func(t *testing.T) {
+		extra := records[2]
+		extra.TimeLocal = extra.TimeLocal.Add(time.Minute)
+
+		options.Mode = domain.ExportAppend
+
+		require.NoError(t, exporter.Export(records, options))
+		require.NoError(t, exporter.Export(append(records, extra), options))
+
+		assert.Equal(t, 4, countRows(t, path, "SELECT COUNT(*) FROM records"))
+	})
+
+	t.Run("AppendSameLinesFromAnotherSource", func(t *testing.T) {
+		sourced := func(source string) []domain.LogRecord {
+			copied := slices.Clone(records)
+
+			for ind := range copied {
+				copied[ind].Source, copied[ind].Offset = source, int64(ind*100)
+			}
+
+			return copied
+		}
+
+		options.Mode = domain.ExportAppend
+
+		require.NoError(t, exporter.Export(sourced("/var/log/a.log"), options))
+		require.NoError(t, exporter.Export(sourced("/var/log/b.log"), options))
+		require.NoError(t, exporter.Export(sourced("/var/log/a.log"), options))
+
+		assert.Equal(t, 10, countRows(t, path, "SELECT COUNT(*) FROM records"))
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		options.Mode = domain.ExportReplace
+
+		require.NoError(t, exporter.Export(records[:1], options))
+
+		assert.Equal(t, 1, countRows(t, path, "SELECT COUNT(*) FROM records"))
+	})
+}
+
+func readParquet(t *testing.T, path string) []parquetRecord {
+	t.Helper()
+
+	rows, err := parquet.ReadFile[parquetRecord](path)
+	require.NoError(t, err)
+
+	return rows
+}
+
+func TestExporter_Parquet(t *testing.T) {
+	exporter := &Exporter{batchSize: 2}
+	records := createRecords()
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	options := domain.ExportOptions{Format: domain.ExportParquet, Output: path, Mode: domain.ExportCreate, RowGroupSize: 2}
+
+	require.NoError(t, exporter.Export(records, options))
+
+	rows := readParquet(t, path)
+	require.Len(t, rows, 3)
+	assert.Equal(t, records[0].TimeLocal, rows[0].Time)
+	assert.Equal(t, int32(404), rows[2].Status)
+	assert.Equal(t, int64(512), rows[2].BodyBytesSent)
+	assert.Equal(t, "Firefox", rows[1].Browser)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	require.NoError(t, err)
+
+	parquetFile, err := parquet.OpenFile(file, info.Size())
+	require.NoError(t, err)
+
+	assert.Len(t, parquetFile.RowGroups(), 2)
+
+	column, ok := parquetFile.Schema().Lookup("time")
+	require.True(t, ok)
+	assert.Equal(t, "TIMESTAMP(isAdjustedToUTC=true,unit=MILLIS)", column.Node.Type().LogicalType().String())
+
+	t.Run("ExistingFile", func(t *testing.T) {
+		assert.Error(t, exporter.Export(records, options))
+
+		options.Mode = domain.ExportAppend
+		assert.Error(t, exporter.Export(records, options))
+	})
+
+	t.Run("Partitioned", func(t *testing.T) {
+		next := records[2]
+		next.TimeLocal = next.TimeLocal.Add(24 * time.Hour)
+
+		dir := filepath.Join(t.TempDir(), "records")
+		options := domain.ExportOptions{Format: domain.ExportParquet, Output: dir, Mode: domain.ExportAppend, Partition: domain.PartitionDate}
+
+		require.NoError(t, exporter.Export(append(records, next), options))
+		require.NoError(t, exporter.Export([]domain.LogRecord{next}, options))
+
+		assert.Len(t, readParquet(t, filepath.Join(dir, "date=2024-08-31", "part-00000.parquet")), 3)
+		assert.Len(t, readParquet(t, filepath.Join(dir, "date=2024-09-01", "part-00000.parquet")), 1)
+		assert.Len(t, readParquet(t, filepath.Join(dir, "date=2024-09-01", "part-00001.parquet")), 1)
+
+		options.Mode = domain.ExportReplace
+
+		require.NoError(t, exporter.Export(records[:1], options))
+
+		parts, err := filepath.Glob(filepath.Join(dir, "*", "*.parquet"))
+		require.NoError(t, err)
+		assert.Len(t, parts, 1)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600))
+		assert.Error(t, exporter.Export(records, options), "Ожидалось, что каталог с посторонними файлами не будет удалён")
+	})
+}
@@ -0,0 +1,161 @@
+package exporter
+
+import (
+	domain "analyzer/internal/domain"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	partitionPrefix   = "date="
+	partitionLayout   = "2006-01-02"
+	parquetPartFormat = "part-%05d.parquet"
+)
+
+type parquetRecord struct {
+	Time          time.Time `parquet:"time,timestamp(millisecond)"`
+	RemoteAddr    string    `parquet:"remote_addr,dict"`
+	RemoteUser    string    `parquet:"remote_user,dict"`
+	Method        string    `parquet:"method,dict"`
+	URL           string    `parquet:"url,dict"`
+	Protocol      string    `parquet:"protocol,dict"`
+	Status        int32     `parquet:"status"`
+	BodyBytesSent int64     `parquet:"body_bytes_sent"`
+	Referer       string    `parquet:"referer,dict"`
+	UserAgent     string    `parquet:"user_agent,dict"`
+	ForwardedFor  string    `parquet:"forwarded_for,dict"`
+	RealIP        string    `parquet:"real_ip,dict"`
+	PeerAddr      string    `parquet:"peer_addr,dict"`
+	Browser       string    `parquet:"browser,dict"`
+	OS            string    `parquet:"os,dict"`
+	Device        string    `parquet:"device,dict"`
+	Bot           bool      `parquet:"bot"`
+	BotName       string    `parquet:"bot_name,dict"`
+	Country       string    `parquet:"country,dict"`
+	City          string    `parquet:"city,dict"`
+	ASN           int64     `parquet:"asn"`
+	Organization  string    `parquet:"organization,dict"`
+}
+
+func newParquetRecord(record *domain.LogRecord) parquetRecord {
+	return parquetRecord{
+		Time:          record.TimeLocal.UTC(),
+		RemoteAddr:    record.RemoteAddr,
+		RemoteUser:    record.RemoteUser,
+		Method:        record.Method,
+		URL:           record.URL,
+		Protocol:      record.ProtocolVersion,
+		Status:        int32(record.Status),
+		BodyBytesSent: int64(record.BodyBytesSent),
+		Referer:       record.Referer,
+		UserAgent:     record.UserAgent,
+		ForwardedFor:  record.ForwardedFor,
+		RealIP:        record.RealIP,
+		PeerAddr:      record.PeerAddr,
+		Browser:       record.Agent.Browser,
+		OS:            record.Agent.OS,
+		Device:        record.Agent.Device,
+		Bot:           record.Agent.Bot,
+		BotName:       record.Agent.BotName,
+		Country:       record.Geo.Country,
+		City:          record.Geo.City,
+		ASN:           int64(record.Geo.ASN),
+		Organization:  record.Geo.Organization,
+	}
+}
+
+func (exporter *Exporter) exportParquet(records []domain.LogRecord, options domain.ExportOptions) error {
+	rowGroupSize := options.RowGroupSize
+	if rowGroupSize <= 0 {
+		rowGroupSize = domain.DefaultRowGroupSize
+	}
+
+	if options.Partition == "" {
+		return exporter.writeParquet(options.Output, records, rowGroupSize)
+	}
+
+	partitions := make(map[string][]domain.LogRecord)
+
+	for ind := range records {
+		date := records[ind].TimeLocal.UTC().Format(partitionLayout)
+		partitions[date] = append(partitions[date], records[ind])
+	}
+
+	dates := make([]string, 0, len(partitions))
+	for date := range partitions {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	for _, date := range dates {
+		dir := filepath.Join(options.Output, partitionPrefix+date)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("не удалось создать каталог %s: %v", dir, err)
+		}
+
+		path, err := nextPartPath(dir)
+		if err != nil {
+			return err
+		}
+
+		if err := exporter.writeParquet(path, partitions[date], rowGroupSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (exporter *Exporter) writeParquet(path string, records []domain.LogRecord, rowGroupSize int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл parquet %s: %v", path, err)
+	}
+
+	defer file.Close()
+
+	writer := parquet.NewGenericWriter[parquetRecord](file,
+		parquet.MaxRowsPerRowGroup(int64(rowGroupSize)), parquet.Compression(&parquet.Snappy))
+	rows := make([]parquetRecord, 0, exporter.batchSize)
+
+	for start := 0; start < len(records); start += exporter.batchSize {
+		end := min(start+exporter.batchSize, len(records))
+		rows = rows[:0]
+
+		for ind := start; ind < end; ind++ {
+			rows = append(rows, newParquetRecord(&records[ind]))
+		}
+
+		if _, err := writer.Write(rows); err != nil {
+			return fmt.Errorf("не удалось записать записи в файл parquet %s: %v", path, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("не удалось записать записи в файл parquet %s: %v", path, err)
+	}
+
+	return file.Close()
+}
+
+func nextPartPath(dir string) (string, error) {
+	for part := 0; ; part++ {
+		path := filepath.Join(dir, fmt.Sprintf(parquetPartFormat, part))
+
+		_, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("не удалось проверить файл %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package exporter
+
+import (
+	domain "analyzer/internal/domain"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS records (
+		id              TEXT PRIMARY KEY,
+		time            TEXT NOT NULL,
+		remote_addr     TEXT NOT NULL,
+		remote_user     TEXT NOT NULL,
+		method          TEXT NOT NULL,
+		url             TEXT NOT NULL,
+		protocol        TEXT NOT NULL,
+		status          INTEGER NOT NULL,
+		body_bytes_sent INTEGER NOT NULL,
+		referer         TEXT NOT NULL,
+		user_agent      TEXT NOT NULL,
+		forwarded_for   TEXT NOT NULL,
+		real_ip         TEXT NOT NULL,
+		peer_addr       TEXT NOT NULL,
+		browser         TEXT NOT NULL,
+		os              TEXT NOT NULL,
+		device          TEXT NOT NULL,
+		bot             INTEGER NOT NULL,
+		bot_name        TEXT NOT NULL,
+		country         TEXT NOT NULL,
+		city            TEXT NOT NULL,
+		asn             INTEGER NOT NULL,
+		organization    TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS records_time ON records (time)`,
+	`CREATE INDEX IF NOT EXISTS records_status ON records (status)`,
+	`CREATE INDEX IF NOT EXISTS records_url ON records (url)`,
+}
+
+const sqliteInsert = `INSERT OR IGNORE INTO records VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+func (exporter *Exporter) exportSQLite(records []domain.LogRecord, output string) error {
+	db, err := sql.Open("sqlite", output)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть базу SQLite %s: %v", output, err)
+	}
+
+	defer db.Close()
+
+	for _, statement := range sqliteSchema {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("не удалось создать таблицу в базе SQLite %s: %v", output, err)
+		}
+	}
+
+	keys := recordKeys(records)
+
+	for start := 0; start < len(records); start += exporter.batchSize {
+		end := min(start+exporter.batchSize, len(records))
+
+		if err := insertBatch(db, records[start:end], keys[start:end]); err != nil {
+			return fmt.Errorf("не удалось записать записи в базу SQLite %s: %v", output, err)
+		}
+	}
+
+	return nil
+}
+
+func insertBatch(db *sql.DB, records []domain.LogRecord, keys []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	statement, err := tx.Prepare(sqliteInsert)
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	for ind := range records {
+		record := &records[ind]
+
+		_, err := statement.Exec(keys[ind], record.TimeLocal.UTC().Format(sqliteTimeLayout),
+			record.RemoteAddr, record.RemoteUser, record.Method, record.URL, record.ProtocolVersion,
+			record.Status, record.BodyBytesSent, record.Referer, record.UserAgent,
+			record.ForwardedFor, record.RealIP, record.PeerAddr,
+			record.Agent.Browser, record.Agent.OS, record.Agent.Device, record.Agent.Bot, record.Agent.BotName,
+			record.Geo.Country, record.Geo.City, record.Geo.ASN, record.Geo.Organization)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func recordKeys(records []domain.LogRecord) []string {
+	keys := make([]string, 0, len(records))
+	occurrences := make(map[string]int)
+
+	for ind := range records {
+		record := &records[ind]
+
+		fields := []string{
+			record.Source, strconv.FormatInt(record.Offset, 10),
+			record.TimeLocal.UTC().Format(sqliteTimeLayout), record.RemoteAddr, record.RemoteUser,
+			record.Method, record.URL, record.ProtocolVersion, strconv.Itoa(record.Status),
+			strconv.Itoa(record.BodyBytesSent), record.Referer, record.UserAgent,
+			record.ForwardedFor, record.RealIP, record.PeerAddr,
+		}
+
+		sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+		key := hex.EncodeToString(sum[:16])
+
+		if record.Source != "" {
+			keys = append(keys, key)
+			continue
+		}
+
+		occurrences[key]++
+		keys = append(keys, key+"-"+strconv.Itoa(occurrences[key]))
+	}
+
+	return keys
+}
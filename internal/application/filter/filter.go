@@ -2,6 +2,8 @@ package filter
 
 import (
 	domain "analyzer/internal/domain"
+	"fmt"
+	"net/netip"
 	"strconv"
 )
 
@@ -16,7 +18,7 @@ func (filter *LogFilter) Filter(records []domain.LogRecord, config *domain.Confi
 
 	for ind := range records {
 		record := &records[ind]
-		if filter.checkFilterFields(record, config) && filter.checkTime(record, config) {
+		if filter.checkFilterFields(record, config) && filter.checkTime(record, config) && filter.checkClient(record, config) {
 			filteredRecords = append(filteredRecords, *record)
 		}
 	}
@@ -34,6 +36,10 @@ func (filter *LogFilter) checkFilterFields(record *domain.LogRecord, config *dom
 		if config.FilterValue.MatchString(record.RemoteAddr) {
 			return true
 		}
+	case "peer":
+		if config.FilterValue.MatchString(record.PeerAddr) {
+			return true
+		}
 	case "user":
 		if config.FilterValue.MatchString(record.RemoteUser) {
 			return true
@@ -58,6 +64,19 @@ func (filter *LogFilter) checkFilterFields(record *domain.LogRecord, config *dom
 		if config.FilterValue.MatchString(record.Referer) {
 			return true
 		}
+	case "bot":
+		if config.FilterValue.MatchString(strconv.FormatBool(record.Agent.Bot)) {
+			return true
+		}
+	case "country":
+		if config.FilterValue.MatchString(record.Geo.Country) || config.FilterValue.MatchString(record.Geo.CountryName) {
+			return true
+		}
+	case "asn":
+		if config.FilterValue.MatchString(fmt.Sprintf("AS%d", record.Geo.ASN)) ||
+			config.FilterValue.MatchString(record.Geo.Organization) {
+			return true
+		}
 	case "":
 		return true
 	}
@@ -74,3 +93,16 @@ func (filter *LogFilter) checkTime(logRecord *domain.LogRecord, config *domain.C
 
 	return false
 }
+
+func (filter *LogFilter) checkClient(record *domain.LogRecord, config *domain.Config) bool {
+	if !config.ProfileClient.IsValid() {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(record.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	return config.ProfileClient.Contains(addr.Unmap())
+}
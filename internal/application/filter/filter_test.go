@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"net/netip"
 	"regexp"
 	"testing"
 	"time"
@@ -120,6 +121,49 @@ func TestLogFilter(t *testing.T) {
 		assert.Len(t, filteredRecords, 3, "Ожидалось 3 записи, удовлетворяющие фильтру по времени")
 	})
 
+	t.Run("FilterByBot", func(t *testing.T) {
+		botRecords := createTestLogRecords()
+		botRecords[2].Agent.Bot = true
+
+		config := &domain.Config{
+			FilterField: "bot",
+			FilterValue: regexp.MustCompile("^true$"),
+		}
+
+		filteredRecords := filter.Filter(botRecords, config)
+		assert.Len(t, filteredRecords, 1, "Ожидалась 1 запись, удовлетворяющая фильтру по ботам")
+		assert.Equal(t, "192.168.1.3", filteredRecords[0].RemoteAddr)
+	})
+
+	t.Run("FilterByGeo", func(t *testing.T) {
+		geoRecords := createTestLogRecords()
+		geoRecords[0].Geo = domain.Geo{Country: "DE", CountryName: "Германия", ASN: 64500, Organization: "Example Hosting"}
+		geoRecords[3].Geo = domain.Geo{Country: "FR", CountryName: "Франция", ASN: 64501, Organization: "Other Net"}
+
+		countryConfig := &domain.Config{
+			FilterField: "country",
+			FilterValue: regexp.MustCompile("^(DE|Франция)$"),
+		}
+		asnConfig := &domain.Config{
+			FilterField: "asn",
+			FilterValue: regexp.MustCompile("^AS64500$"),
+		}
+
+		assert.Len(t, filter.Filter(geoRecords, countryConfig), 2, "Ожидалось 2 записи, удовлетворяющие фильтру по стране")
+		assert.Len(t, filter.Filter(geoRecords, asnConfig), 1, "Ожидалась 1 запись, удовлетворяющая фильтру по ASN")
+	})
+
+	t.Run("FilterByProfileClient", func(t *testing.T) {
+		single := &domain.Config{ProfileClient: netip.MustParsePrefix("192.168.1.2/32")}
+		subnet := &domain.Config{ProfileClient: netip.MustParsePrefix("192.168.1.0/30")}
+
+		filteredRecords := filter.Filter(records, single)
+		assert.Len(t, filteredRecords, 1, "Ожидалась 1 запись выбранного клиента")
+		assert.Equal(t, "192.168.1.2", filteredRecords[0].RemoteAddr)
+
+		assert.Len(t, filter.Filter(records, subnet), 3, "Ожидалось 3 записи клиентов из подсети")
+	})
+
 	t.Run("FilterByInvalidField", func(t *testing.T) {
 		config := &domain.Config{
 			FilterField: "referer",
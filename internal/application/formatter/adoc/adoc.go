@@ -20,9 +20,33 @@ func (w *Formatter) WriteGeneralInfo(builder *strings.Builder, report *domain.Lo
 	w.writeDate(builder, "Начальная дата", report.StartDate)
 	w.writeDate(builder, "Конечная дата", report.EndDate)
 	fmt.Fprintf(builder, "| Количество запросов | %s\n", output.FormatNumber(report.TotalRequests))
+
+	if report.DroppedRecords > 0 {
+		fmt.Fprintf(builder, "| Старых записей отброшено (--max-records) | %s\n", output.FormatNumber(report.DroppedRecords))
+	}
+
 	fmt.Fprintf(builder, "| Средний размер ответа | %sb\n", output.FormatNumber(report.AvgBodySize))
 	fmt.Fprintf(builder, "| 95p размера ответа | %sb\n", output.FormatNumber(report.Percentile95Size))
 	fmt.Fprintf(builder, "| Среднее время между запросами | %s\n", report.AvgTimeBetweenRequests)
+	fmt.Fprintf(builder, "| Доля ошибок 4xx | %s\n", output.FormatPercent(report.ClientErrors, report.TotalRequests))
+	fmt.Fprintf(builder, "| Доля ошибок 5xx | %s\n", output.FormatPercent(report.ServerErrors, report.TotalRequests))
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteApproximation(builder *strings.Builder, report *domain.LogReport) {
+	approx := &report.Approximation
+
+	if approx.Capacity == 0 {
+		return
+	}
+
+	fmt.Fprintf(builder, "== Точность топов (--approx %d)\n\n", approx.Capacity)
+	builder.WriteString("[cols=2]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Топ | Завышение не более\n")
+	fmt.Fprintf(builder, "| IP-адреса | %s\n", output.FormatNumber(approx.IPError))
+	fmt.Fprintf(builder, "| Ресурсы | %s\n", output.FormatNumber(approx.ResourceError))
+	fmt.Fprintf(builder, "| User-agent | %s\n", output.FormatNumber(approx.UserAgentError))
 	builder.WriteString("|====\n\n")
 }
 
@@ -70,6 +94,379 @@ func (w *Formatter) WriteTopIPAddresses(builder *strings.Builder, report *domain
 	builder.WriteString("|====\n\n")
 }
 
+func (w *Formatter) WriteGeo(builder *strings.Builder, report *domain.LogReport) {
+	w.writeValueCounts(builder, "Топ стран", "Страна", report.Geo.TopCountries, report.TotalRequests)
+	w.writeValueCounts(builder, "Топ сетей", "Сеть (ASN)", report.Geo.TopNetworks, report.TotalRequests)
+}
+
+func (w *Formatter) WriteUserAgents(builder *strings.Builder, report *domain.LogReport) {
+	agents := &report.UserAgents
+	total := agents.BotRequests + agents.HumanRequests
+
+	if total == 0 {
+		return
+	}
+
+	w.writeValueCounts(builder, "Браузеры", "Браузер", agents.TopBrowsers, agents.HumanRequests)
+	w.writeValueCounts(builder, "Операционные системы", "ОС", agents.TopOperatingSystems, agents.HumanRequests)
+	w.writeValueCounts(builder, "Типы устройств", "Устройство", agents.DeviceTypes, agents.HumanRequests)
+
+	builder.WriteString("== Боты и люди\n\n")
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Клиенты | Количество | Доля\n")
+	fmt.Fprintf(builder, "| Люди | %s | %s\n",
+		output.FormatNumber(agents.HumanRequests), output.FormatPercent(agents.HumanRequests, total))
+	fmt.Fprintf(builder, "| Боты | %s | %s\n",
+		output.FormatNumber(agents.BotRequests), output.FormatPercent(agents.BotRequests, total))
+	builder.WriteString("|====\n\n")
+
+	w.writeValueCounts(builder, "Топ ботов", "Бот", agents.TopBots, agents.BotRequests)
+	w.writeValueCounts(builder, "Топ user-agent", "User-agent", agents.TopAgents, total)
+}
+
+func (w *Formatter) WriteReferers(builder *strings.Builder, report *domain.LogReport) {
+	referers := &report.Referers
+	total := referers.DirectRequests + referers.InternalRequests + referers.ExternalRequests
+
+	if total == 0 {
+		return
+	}
+
+	builder.WriteString("== Источники переходов\n\n")
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Источник | Количество | Доля\n")
+	fmt.Fprintf(builder, "| Прямые | %s | %s\n",
+		output.FormatNumber(referers.DirectRequests), output.FormatPercent(referers.DirectRequests, total))
+	fmt.Fprintf(builder, "| Внутренние | %s | %s\n",
+		output.FormatNumber(referers.InternalRequests), output.FormatPercent(referers.InternalRequests, total))
+	fmt.Fprintf(builder, "| Внешние | %s | %s\n",
+		output.FormatNumber(referers.ExternalRequests), output.FormatPercent(referers.ExternalRequests, total))
+	builder.WriteString("|====\n\n")
+
+	w.writeValueCounts(builder, "Топ ссылающихся доменов", "Домен", referers.TopDomains, total-referers.DirectRequests)
+	w.writeValueCounts(builder, "Переходы из поисковых систем", "Поисковая система",
+		referers.TopSearchEngines, referers.ExternalRequests)
+
+	if len(referers.TopLandingPairs) == 0 {
+		return
+	}
+
+	builder.WriteString("== Топ переходов на страницы\n\n")
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Источник | Страница | Количество\n")
+
+	for _, pair := range referers.TopLandingPairs {
+		fmt.Fprintf(builder, "| `%s` | `%s` | %s\n", pair.Referer, pair.URL, output.FormatNumber(pair.Count))
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteSessions(builder *strings.Builder, report *domain.LogReport) {
+	sessions := &report.Sessions
+
+	if sessions.Sessions == 0 {
+		return
+	}
+
+	builder.WriteString("== Сессии\n\n")
+	builder.WriteString("[cols=2]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Метрика | Значение\n")
+	fmt.Fprintf(builder, "| Интервал неактивности | %s\n", sessions.Gap)
+	fmt.Fprintf(builder, "| Количество сессий | %s\n", output.FormatNumber(sessions.Sessions))
+	fmt.Fprintf(builder, "| Уникальных посетителей | %s\n", output.FormatNumber(sessions.Visitors))
+	fmt.Fprintf(builder, "| Средняя длительность сессии | %s\n", sessions.AvgDuration.Round(time.Second))
+	fmt.Fprintf(builder, "| Страниц за сессию | %.2f\n", sessions.AvgPages)
+	fmt.Fprintf(builder, "| Доля отказов | %s\n", output.FormatPercent(sessions.BounceSessions, sessions.Sessions))
+	builder.WriteString("|====\n\n")
+
+	w.writeValueCounts(builder, "Топ страниц входа", "Ресурс", sessions.TopEntryURLs, sessions.Sessions)
+	w.writeValueCounts(builder, "Топ страниц выхода", "Ресурс", sessions.TopExitURLs, sessions.Sessions)
+	w.writeValueCounts(builder, "Популярные пути навигации", "Путь", sessions.TopPaths, sessions.Sessions)
+}
+
+func (w *Formatter) WriteFunnel(builder *strings.Builder, report *domain.LogReport) {
+	funnel := &report.Funnel
+
+	if len(funnel.Steps) == 0 {
+		return
+	}
+
+	fmt.Fprintf(builder, "== Воронка (окно %s)\n\n", funnel.Window)
+	builder.WriteString("[cols=5]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Шаг | Ресурс | Клиентов | Конверсия из предыдущего шага | Конверсия из первого шага\n")
+
+	for i, step := range funnel.Steps {
+		previous := funnel.Steps[max(0, i-1)].Clients
+
+		fmt.Fprintf(builder, "| %d | `%s` | %s | %s | %s\n", i+1, step.URL, output.FormatNumber(step.Clients),
+			output.FormatPercent(step.Clients, previous), output.FormatPercent(step.Clients, funnel.Steps[0].Clients))
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteStatusClasses(builder *strings.Builder, report *domain.LogReport) {
+	if len(report.StatusClasses) == 0 {
+		return
+	}
+
+	builder.WriteString("== Классы кодов ответа\n\n")
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Класс | Количество | Доля\n")
+
+	for _, class := range report.StatusClasses {
+		fmt.Fprintf(builder, "| %s | %s | %s\n",
+			class.Class, output.FormatNumber(class.Count), output.FormatPercent(class.Count, report.TotalRequests))
+	}
+
+	builder.WriteString("|====\n\n")
+
+	builder.WriteString("== Топ ресурсов по классам ответа\n\n")
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Класс | Ресурс | Количество\n")
+
+	for _, class := range report.StatusClasses {
+		for _, value := range class.TopURLs {
+			fmt.Fprintf(builder, "| %s | `%s` | %s\n", class.Class, value.Value, output.FormatNumber(value.Count))
+		}
+	}
+
+	builder.WriteString("|====\n\n")
+
+	builder.WriteString("== Топ клиентов по классам ответа\n\n")
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Класс | IP-адрес | Количество\n")
+
+	for _, class := range report.StatusClasses {
+		for _, value := range class.TopClients {
+			fmt.Fprintf(builder, "| %s | %s | %s\n", class.Class, value.Value, output.FormatNumber(value.Count))
+		}
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteTimeline(builder *strings.Builder, report *domain.LogReport) {
+	if len(report.Timeline) == 0 {
+		return
+	}
+
+	fmt.Fprintf(builder, "== Активность по интервалам (%s)\n\n", report.BucketSize)
+	builder.WriteString("[cols=6]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Интервал | Запросов | 4xx | 5xx | Доля ошибок | Трафик\n")
+
+	for _, bucket := range report.Timeline {
+		if bucket.Requests == 0 {
+			continue
+		}
+
+		fmt.Fprintf(builder, "| %s | %s | %s | %s | %s | %s\n",
+			bucket.Start.Format("02.01.2006 15:04:05"), output.FormatNumber(bucket.Requests),
+			output.FormatNumber(bucket.ClientErrors), output.FormatNumber(bucket.ServerErrors),
+			output.FormatPercent(bucket.ClientErrors+bucket.ServerErrors, bucket.Requests), output.FormatBytes(bucket.Bytes))
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteTraffic(builder *strings.Builder, report *domain.LogReport) {
+	traffic := &report.Traffic
+
+	if report.TotalRequests == 0 {
+		return
+	}
+
+	builder.WriteString("== Трафик\n\n")
+	builder.WriteString("[cols=2]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Метрика | Значение\n")
+	fmt.Fprintf(builder, "| Всего передано | %s (%sb)\n",
+		output.FormatBytes(traffic.TotalBytes), output.FormatNumber(traffic.TotalBytes))
+
+	if seconds := int64(report.BucketSize / time.Second); seconds > 0 {
+		fmt.Fprintf(builder, "| Пиковый интервал | %s\n", traffic.PeakBucketStart.Format("02.01.2006 15:04:05"))
+		fmt.Fprintf(builder, "| Трафик в пиковом интервале | %s\n", output.FormatBytes(traffic.PeakBytes))
+		fmt.Fprintf(builder, "| Пиковая пропускная способность | %s\n",
+			output.FormatByteRate(float64(traffic.PeakBytes)/float64(seconds)))
+	}
+
+	builder.WriteString("|====\n\n")
+
+	w.writeByteCounts(builder, "Топ ресурсов по трафику", "Ресурс", traffic.TopURLs, traffic.TotalBytes)
+	w.writeByteCounts(builder, "Топ клиентов по трафику", "IP-адрес", traffic.TopClients, traffic.TotalBytes)
+}
+
+func (w *Formatter) writeByteCounts(builder *strings.Builder, title, label string, values []domain.ByteCount, total int64) {
+	if len(values) == 0 {
+		return
+	}
+
+	fmt.Fprintf(builder, "== %s\n\n", title)
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	fmt.Fprintf(builder, "| %s | Трафик | Доля\n", label)
+
+	for _, value := range values {
+		fmt.Fprintf(builder, "| `%s` | %s | %s\n",
+			value.Value, output.FormatBytes(value.Bytes), output.FormatPercent(value.Bytes, total))
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteAnomalies(builder *strings.Builder, report *domain.LogReport) {
+	if len(report.Anomalies) == 0 {
+		return
+	}
+
+	builder.WriteString("== Аномалии\n\n")
+	builder.WriteString("[cols=5]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Интервал | Метрика | Ожидалось | Наблюдалось | Описание\n")
+
+	for _, anomaly := range report.Anomalies {
+		fmt.Fprintf(builder, "| %s — %s | %s | %s | %s | %s\n",
+			anomaly.Start.Format("02.01.2006 15:04:05"), anomaly.End.Format("02.01.2006 15:04:05"), anomaly.Metric,
+			w.metricValue(anomaly.Kind, anomaly.Expected), w.metricValue(anomaly.Kind, anomaly.Observed), anomaly.Description)
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteSecurity(builder *strings.Builder, report *domain.LogReport) {
+	security := &report.Security
+
+	if len(security.Signals) == 0 {
+		return
+	}
+
+	w.writeValueCounts(builder, "Сигналы безопасности", "Сигнал", security.Signals, report.TotalRequests)
+
+	if len(security.Clients) == 0 {
+		return
+	}
+
+	builder.WriteString("== Подозрительные клиенты\n\n")
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| IP-адрес | Запросов | Сигналы\n")
+
+	for _, client := range security.Clients {
+		fmt.Fprintf(builder, "| %s | %s | %s\n", client.IP, output.FormatNumber(client.Requests), w.signalList(client.Signals))
+	}
+
+	builder.WriteString("|====\n\n")
+
+	for _, client := range security.Clients {
+		fmt.Fprintf(builder, "=== Свидетельства: %s\n\n", client.IP)
+		builder.WriteString("----\n")
+
+		for _, line := range client.Evidence {
+			builder.WriteString(line + "\n")
+		}
+
+		builder.WriteString("----\n\n")
+	}
+}
+
+func (w *Formatter) signalList(signals []domain.ValueCount) string {
+	items := make([]string, 0, len(signals))
+	for _, signal := range signals {
+		items = append(items, fmt.Sprintf("%s (%s)", signal.Value, output.FormatNumber(signal.Count)))
+	}
+
+	return strings.Join(items, ", ")
+}
+
+func (w *Formatter) WriteProfileSummary(builder *strings.Builder, report *domain.LogReport) {
+	profile := report.Profile
+
+	builder.WriteString("== Профиль клиента\n\n")
+	builder.WriteString("[cols=2]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Метрика | Значение\n")
+
+	if profile.Client != "" {
+		fmt.Fprintf(builder, "| Клиент | %s\n", profile.Client)
+	}
+
+	w.writeFileOrURLNames(builder, report.FileNames, report.URLName)
+	fmt.Fprintf(builder, "| Первый запрос | %s\n", profile.FirstSeen.Format("02.01.2006 15:04:05"))
+	fmt.Fprintf(builder, "| Последний запрос | %s\n", profile.LastSeen.Format("02.01.2006 15:04:05"))
+	fmt.Fprintf(builder, "| Количество запросов | %s\n", output.FormatNumber(profile.Requests))
+	fmt.Fprintf(builder, "| Передано | %s\n", output.FormatBytes(profile.Bytes))
+
+	if minutes := profile.LastSeen.Sub(profile.FirstSeen).Minutes(); minutes > 0 {
+		fmt.Fprintf(builder, "| Запросов в минуту | %.2f\n", float64(profile.Requests)/minutes)
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) WriteProfileDistributions(builder *strings.Builder, report *domain.LogReport) {
+	profile := report.Profile
+
+	w.writeValueCounts(builder, "Адреса клиента", "IP-адрес", profile.Addresses, profile.Requests)
+	w.writeValueCounts(builder, "Коды ответа клиента", "Код", profile.Statuses, profile.Requests)
+	w.writeValueCounts(builder, "Методы клиента", "Метод", profile.Methods, profile.Requests)
+	w.writeValueCounts(builder, "Ресурсы клиента", "Ресурс", profile.URLs, profile.Requests)
+	w.writeValueCounts(builder, "User-agent клиента", "User-agent", profile.UserAgents, profile.Requests)
+}
+
+func (w *Formatter) WriteProfileActivity(builder *strings.Builder, report *domain.LogReport) {
+	if report.Profile == nil || len(report.Profile.Timeline) == 0 {
+		return
+	}
+
+	profile := report.Profile
+
+	fmt.Fprintf(builder, "== Активность клиента по интервалам (%s)\n\n", profile.BucketSize)
+	builder.WriteString("[cols=6]\n")
+	builder.WriteString("|====\n")
+	builder.WriteString("| Интервал | Запросов | Запросов в минуту | 4xx | 5xx | Трафик\n")
+
+	for _, bucket := range profile.Timeline {
+		if bucket.Requests == 0 {
+			continue
+		}
+
+		fmt.Fprintf(builder, "| %s | %s | %.2f | %s | %s | %s\n",
+			bucket.Start.Format("02.01.2006 15:04:05"), output.FormatNumber(bucket.Requests),
+			float64(bucket.Requests)/profile.BucketSize.Minutes(), output.FormatNumber(bucket.ClientErrors),
+			output.FormatNumber(bucket.ServerErrors), output.FormatBytes(bucket.Bytes))
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
+func (w *Formatter) writeValueCounts(builder *strings.Builder, title, label string, values []domain.ValueCount, total int) {
+	if len(values) == 0 {
+		return
+	}
+
+	fmt.Fprintf(builder, "== %s\n\n", title)
+	builder.WriteString("[cols=3]\n")
+	builder.WriteString("|====\n")
+	fmt.Fprintf(builder, "| %s | Количество | Доля\n", label)
+
+	for _, value := range values {
+		fmt.Fprintf(builder, "| %s | %s | %s\n",
+			value.Value, output.FormatNumber(value.Count), output.FormatPercent(value.Count, total))
+	}
+
+	builder.WriteString("|====\n\n")
+}
+
 func (w *Formatter) writeFileOrURLNames(builder *strings.Builder, fileNames []string, urlName string) {
 	if len(fileNames) == 0 {
 		fmt.Fprintf(builder, "| URL | %s\n", "`"+urlName+"`")
@@ -4,6 +4,7 @@ import (
 	adoc "analyzer/internal/application/formatter/adoc"
 	markdown "analyzer/internal/application/formatter/markdown"
 	"analyzer/internal/domain"
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -11,13 +12,35 @@ import (
 const (
 	formatADOC     = "adoc"
 	formatMarkdown = "markdown"
+	formatJSON     = "json"
 )
 
 type formatWriter interface {
 	WriteGeneralInfo(builder *strings.Builder, report *domain.LogReport)
+	WriteApproximation(builder *strings.Builder, report *domain.LogReport)
 	WriteRequestedResources(builder *strings.Builder, report *domain.LogReport)
 	WriteResponseCodes(builder *strings.Builder, report *domain.LogReport)
 	WriteTopIPAddresses(builder *strings.Builder, report *domain.LogReport)
+	WriteGeo(builder *strings.Builder, report *domain.LogReport)
+	WriteUserAgents(builder *strings.Builder, report *domain.LogReport)
+	WriteReferers(builder *strings.Builder, report *domain.LogReport)
+	WriteSessions(builder *strings.Builder, report *domain.LogReport)
+	WriteFunnel(builder *strings.Builder, report *domain.LogReport)
+	WriteStatusClasses(builder *strings.Builder, report *domain.LogReport)
+	WriteTimeline(builder *strings.Builder, report *domain.LogReport)
+	WriteTraffic(builder *strings.Builder, report *domain.LogReport)
+	WriteAnomalies(builder *strings.Builder, report *domain.LogReport)
+	WriteSecurity(builder *strings.Builder, report *domain.LogReport)
+	WriteProfileSummary(builder *strings.Builder, report *domain.LogReport)
+	WriteProfileDistributions(builder *strings.Builder, report *domain.LogReport)
+	WriteProfileActivity(builder *strings.Builder, report *domain.LogReport)
+}
+
+type diffWriter interface {
+	WriteDiffSummary(builder *strings.Builder, reportDiff *domain.ReportDiff)
+	WriteDiffRegressions(builder *strings.Builder, reportDiff *domain.ReportDiff)
+	WriteDiffStatusCodes(builder *strings.Builder, reportDiff *domain.ReportDiff)
+	WriteDiffResources(builder *strings.Builder, reportDiff *domain.ReportDiff)
 }
 
 type Formatter struct{}
@@ -34,16 +57,71 @@ func (formatter *Formatter) Format(report *domain.LogReport, format string) (str
 		writer = &markdown.Formatter{}
 	case formatADOC:
 		writer = &adoc.Formatter{}
+	case formatJSON:
+		return formatter.formatJSON(report)
 	default:
 		return "", fmt.Errorf("неподдерживаемый формат: %s", format)
 	}
 
 	var builder strings.Builder
 
+	if report.Profile != nil {
+		writer.WriteProfileSummary(&builder, report)
+		writer.WriteProfileDistributions(&builder, report)
+		writer.WriteProfileActivity(&builder, report)
+		writer.WriteSecurity(&builder, report)
+
+		return builder.String(), nil
+	}
+
 	writer.WriteGeneralInfo(&builder, report)
+	writer.WriteApproximation(&builder, report)
 	writer.WriteRequestedResources(&builder, report)
 	writer.WriteResponseCodes(&builder, report)
+	writer.WriteStatusClasses(&builder, report)
 	writer.WriteTopIPAddresses(&builder, report)
+	writer.WriteGeo(&builder, report)
+	writer.WriteUserAgents(&builder, report)
+	writer.WriteReferers(&builder, report)
+	writer.WriteSessions(&builder, report)
+	writer.WriteFunnel(&builder, report)
+	writer.WriteTraffic(&builder, report)
+	writer.WriteTimeline(&builder, report)
+	writer.WriteAnomalies(&builder, report)
+	writer.WriteSecurity(&builder, report)
 
 	return builder.String(), nil
 }
+
+func (formatter *Formatter) FormatDiff(reportDiff *domain.ReportDiff, format string) (string, error) {
+	var writer diffWriter
+
+	switch format {
+	case formatMarkdown:
+		writer = &markdown.Formatter{}
+	case formatADOC:
+		writer = &adoc.Formatter{}
+	case formatJSON:
+		return formatter.formatJSON(reportDiff)
+	default:
+		return "", fmt.Errorf("неподдерживаемый формат: %s", format)
+	}
+
+	var builder strings.Builder
+
+	writer.WriteDiffSummary(&builder, reportDiff)
+	writer.WriteDiffRegressions(&builder, reportDiff)
+	writer.WriteDiffStatusCodes(&builder, reportDiff)
+	writer.WriteDiffResources(&builder, reportDiff)
+
+	return builder.String(), nil
+}
+
+func (formatter *Formatter) formatJSON(value any) (string, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("не удалось сформировать JSON: %v", err)
+	}
+
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,106 @@
+package markdown
+
+import (
+	"analyzer/internal/domain"
+	"analyzer/pkg/output"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (w *Formatter) WriteDiffSummary(builder *strings.Builder, reportDiff *domain.ReportDiff) {
+	builder.WriteString("## Сравнение отчётов\n\n")
+	builder.WriteString("| **Метрика** | **Было** | **Стало** | **Изменение** |\n")
+	builder.WriteString("|:---------------------------------|:---------------|:---------------|:---------------|\n")
+
+	fmt.Fprintf(builder, "| Источник | %s | %s | |\n", w.diffSource(&reportDiff.Base), w.diffSource(&reportDiff.Current))
+
+	for i := range reportDiff.Metrics {
+		w.writeMetricDelta(builder, &reportDiff.Metrics[i])
+	}
+
+	builder.WriteString("\n")
+}
+
+func (w *Formatter) WriteDiffRegressions(builder *strings.Builder, reportDiff *domain.ReportDiff) {
+	builder.WriteString("## Регрессии\n\n")
+
+	if len(reportDiff.Regressions) == 0 {
+		builder.WriteString("Регрессий не обнаружено.\n\n")
+		return
+	}
+
+	for _, regression := range reportDiff.Regressions {
+		fmt.Fprintf(builder, "- ⚠️ **%s**\n", regression)
+	}
+
+	builder.WriteString("\n")
+}
+
+func (w *Formatter) WriteDiffStatusCodes(builder *strings.Builder, reportDiff *domain.ReportDiff) {
+	if len(reportDiff.StatusCodes) == 0 {
+		return
+	}
+
+	builder.WriteString("## Изменения кодов ответа\n\n")
+	builder.WriteString("| **Код** | **Было** | **Стало** | **Изменение** |\n")
+	builder.WriteString("|:-----------------------|:---------------|:---------------|:---------------|\n")
+
+	for i := range reportDiff.StatusCodes {
+		w.writeMetricDelta(builder, &reportDiff.StatusCodes[i])
+	}
+
+	builder.WriteString("\n")
+
+	w.writeValueCounts(builder, "Новые коды ответа", "Код", reportDiff.NewStatusCodes, reportDiff.Current.TotalRequests)
+	w.writeValueCounts(builder, "Исчезнувшие коды ответа", "Код", reportDiff.GoneStatusCodes, reportDiff.Base.TotalRequests)
+}
+
+func (w *Formatter) WriteDiffResources(builder *strings.Builder, reportDiff *domain.ReportDiff) {
+	w.writeValueCounts(builder, "Новые ресурсы в топе", "Ресурс", reportDiff.NewResources, reportDiff.Current.TotalRequests)
+	w.writeValueCounts(builder, "Выбывшие из топа ресурсы", "Ресурс", reportDiff.GoneResources, reportDiff.Base.TotalRequests)
+}
+
+func (w *Formatter) writeMetricDelta(builder *strings.Builder, delta *domain.MetricDelta) {
+	name := delta.Name
+	if delta.Regression {
+		name = "⚠️ **" + name + "**"
+	}
+
+	fmt.Fprintf(builder, "| %s | %s | %s | %s |\n",
+		name, w.metricValue(delta.Kind, delta.Base), w.metricValue(delta.Kind, delta.Current), w.metricChange(delta))
+}
+
+func (w *Formatter) metricValue(kind string, value float64) string {
+	switch kind {
+	case domain.MetricBytes:
+		return output.FormatBytes(int64(value))
+	case domain.MetricDuration:
+		return time.Duration(value * float64(time.Second)).Round(time.Millisecond).String()
+	case domain.MetricPercent:
+		return fmt.Sprintf("%.2f%%", value)
+	default:
+		return output.FormatNumber(int64(value))
+	}
+}
+
+func (w *Formatter) metricChange(delta *domain.MetricDelta) string {
+	switch {
+	case delta.Kind == domain.MetricPercent:
+		return fmt.Sprintf("%+.2f п.п.", delta.Delta)
+	case delta.ChangePercent == nil && delta.Current == 0:
+		return "-"
+	case delta.ChangePercent == nil:
+		return "новое"
+	default:
+		return fmt.Sprintf("%+.2f%%", *delta.ChangePercent)
+	}
+}
+
+func (w *Formatter) diffSource(source *domain.ReportSource) string {
+	if len(source.FileNames) == 0 {
+		return "`" + source.URLName + "`"
+	}
+
+	return "`" + strings.Join(source.FileNames, "`, `") + "`"
+}
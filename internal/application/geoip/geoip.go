@@ -0,0 +1,123 @@
+package geoip
+
+import (
+	domain "analyzer/internal/domain"
+	lru "analyzer/pkg/lru"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const cacheSize = 100_000
+
+var nameLanguages = []string{"ru", "en"}
+
+type database interface {
+	Lookup(ip net.IP, result any) error
+	Close() error
+}
+
+type geoRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	ASN          uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+type Resolver struct {
+	databases []database
+
+	mu    sync.Mutex
+	cache *lru.Cache[string, domain.Geo]
+}
+
+func Open(paths []string) (*Resolver, error) {
+	databases := make([]database, 0, len(paths))
+
+	for _, path := range paths {
+		reader, err := maxminddb.Open(path)
+		if err != nil {
+			closeAll(databases)
+			return nil, fmt.Errorf("не удалось открыть базу GeoIP %s: %v", path, err)
+		}
+
+		databases = append(databases, reader)
+	}
+
+	return newResolver(databases), nil
+}
+
+func newResolver(databases []database) *Resolver {
+	return &Resolver{
+		databases: databases,
+		cache:     lru.New[string, domain.Geo](cacheSize),
+	}
+}
+
+func (resolver *Resolver) Lookup(addr string) domain.Geo {
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+
+	if result, exists := resolver.cache.Get(addr); exists {
+		return result
+	}
+
+	result := resolver.lookup(addr)
+	resolver.cache.Add(addr, result)
+
+	return result
+}
+
+func (resolver *Resolver) Close() error {
+	return closeAll(resolver.databases)
+}
+
+func (resolver *Resolver) lookup(addr string) domain.Geo {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return domain.Geo{}
+	}
+
+	var record geoRecord
+
+	for _, database := range resolver.databases {
+		_ = database.Lookup(ip, &record)
+	}
+
+	return domain.Geo{
+		Country:      record.Country.ISOCode,
+		CountryName:  localizedName(record.Country.Names),
+		City:         localizedName(record.City.Names),
+		ASN:          record.ASN,
+		Organization: record.Organization,
+	}
+}
+
+func localizedName(names map[string]string) string {
+	for _, language := range nameLanguages {
+		if name, exists := names[language]; exists {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func closeAll(databases []database) error {
+	var result error
+
+	for _, database := range databases {
+		if err := database.Close(); err != nil && result == nil {
+			result = fmt.Errorf("не удалось закрыть базу GeoIP: %v", err)
+		}
+	}
+
+	return result
+}
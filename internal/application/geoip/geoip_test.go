@@ -0,0 +1,95 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDatabase struct {
+	records map[string]func(record *geoRecord)
+	lookups int
+	closed  bool
+}
+
+func (database *fakeDatabase) Lookup(ip net.IP, result any) error {
+	database.lookups++
+
+	fill, exists := database.records[ip.String()]
+	if !exists {
+		return errors.New("адрес не найден")
+	}
+
+	fill(result.(*geoRecord))
+
+	return nil
+}
+
+func (database *fakeDatabase) Close() error {
+	database.closed = true
+	return nil
+}
+
+func TestResolver_Lookup(t *testing.T) {
+	city := &fakeDatabase{records: map[string]func(record *geoRecord){
+		"203.0.113.7": func(record *geoRecord) {
+			record.Country.ISOCode = "DE"
+			record.Country.Names = map[string]string{"en": "Germany", "ru": "Германия"}
+			record.City.Names = map[string]string{"en": "Berlin"}
+		},
+	}}
+	asn := &fakeDatabase{records: map[string]func(record *geoRecord){
+		"203.0.113.7": func(record *geoRecord) {
+			record.ASN = 64500
+			record.Organization = "Example Hosting"
+		},
+	}}
+
+	resolver := newResolver([]database{city, asn})
+
+	t.Run("MergesDatabases", func(t *testing.T) {
+		assert.Equal(t, domain.Geo{
+			Country:      "DE",
+			CountryName:  "Германия",
+			City:         "Berlin",
+			ASN:          64500,
+			Organization: "Example Hosting",
+		}, resolver.Lookup("203.0.113.7"))
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		resolver.Lookup("203.0.113.7")
+		assert.Equal(t, 1, city.lookups)
+	})
+
+	t.Run("BoundedCache", func(t *testing.T) {
+		for ind := range cacheSize + 100 {
+			resolver.Lookup(fmt.Sprintf("10.%d.%d.%d", ind>>16&255, ind>>8&255, ind&255))
+		}
+
+		assert.Equal(t, cacheSize, resolver.cache.Len())
+	})
+
+	t.Run("UnknownAddress", func(t *testing.T) {
+		assert.Equal(t, domain.Geo{}, resolver.Lookup("198.51.100.1"))
+		assert.Equal(t, domain.Geo{}, resolver.Lookup("not-an-ip"))
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		require.NoError(t, resolver.Close())
+		assert.True(t, city.closed)
+		assert.True(t, asn.closed)
+	})
+}
+
+func TestOpen_MissingDatabase(t *testing.T) {
+	_, err := Open([]string{filepath.Join(t.TempDir(), "missing.mmdb")})
+	assert.Error(t, err)
+}
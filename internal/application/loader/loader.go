@@ -0,0 +1,29 @@
+package loader
+
+import (
+	domain "analyzer/internal/domain"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type ReportLoader struct{}
+
+func NewReportLoader() *ReportLoader {
+	return &ReportLoader{}
+}
+
+func (loader *ReportLoader) Load(path string) (domain.LogReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.LogReport{}, fmt.Errorf("невозможно прочитать отчёт %s: %v", path, err)
+	}
+
+	var report domain.LogReport
+
+	if err := json.Unmarshal(data, &report); err != nil {
+		return domain.LogReport{}, fmt.Errorf("неверный формат сохранённого отчёта %s: %v", path, err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,52 @@
+package loader
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportLoader_Load(t *testing.T) {
+	loader := NewReportLoader()
+
+	t.Run("SavedReport", func(t *testing.T) {
+		report := domain.LogReport{
+			FileNames:              []string{"access.log"},
+			TotalRequests:          3,
+			AvgTimeBetweenRequests: 2 * time.Second,
+			ResponseCodes:          map[int]domain.ResponseCode{200: {Name: "OK", Count: 3}},
+			RequestedResources:     map[string]int{"/": 3},
+		}
+
+		data, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "report.json")
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		loaded, err := loader.Load(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, report, loaded)
+	})
+
+	t.Run("InvalidReport", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "report.json")
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+		_, err := loader.Load(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingReport", func(t *testing.T) {
+		_, err := loader.Load(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
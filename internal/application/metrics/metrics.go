@@ -0,0 +1,223 @@
+package metrics
+
+import (
+	domain "analyzer/internal/domain"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const otherValue = "other"
+
+var (
+	sizeBuckets    = []float64{100, 1 << 10, 10 << 10, 100 << 10, 1 << 20, 10 << 20}
+	latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+type requestKey struct {
+	method string
+	status string
+	route  string
+}
+
+type routeKey struct {
+	method string
+	route  string
+}
+
+type histogram struct {
+	counts []int
+	count  int
+	sum    float64
+}
+
+type Collector struct {
+	mutex     sync.Mutex
+	limits    map[string]int
+	values    map[string]map[string]bool
+	overflows map[string]int
+	requests  map[requestKey]int
+	sizes     map[routeKey]*histogram
+	latencies map[routeKey]*histogram
+	errors    int
+}
+
+func NewCollector(limits map[string]int) *Collector {
+	return &Collector{
+		limits:    limits,
+		values:    make(map[string]map[string]bool),
+		overflows: make(map[string]int),
+		requests:  make(map[requestKey]int),
+		sizes:     make(map[routeKey]*histogram),
+		latencies: make(map[routeKey]*histogram),
+	}
+}
+
+func (collector *Collector) Observe(records []domain.LogRecord) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	for ind := range records {
+		record := &records[ind]
+		path, _, _ := strings.Cut(record.URL, "?")
+
+		method := collector.limit(domain.LabelMethod, record.Method)
+		status := collector.limit(domain.LabelStatus, strconv.Itoa(record.Status))
+		route := collector.limit(domain.LabelRoute, path)
+
+		collector.requests[requestKey{method: method, status: status, route: route}]++
+
+		key := routeKey{method: method, route: route}
+		observe(collector.sizes, key, sizeBuckets, float64(record.BodyBytesSent))
+
+		if record.HasRequestTime {
+			observe(collector.latencies, key, latencyBuckets, record.RequestTime.Seconds())
+		}
+	}
+}
+
+func (collector *Collector) AddError() {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	collector.errors++
+}
+
+func (collector *Collector) limit(label, value string) string {
+	values := collector.values[label]
+	if values == nil {
+		values = make(map[string]bool)
+		collector.values[label] = values
+	}
+
+	if values[value] {
+		return value
+	}
+
+	if len(values) >= collector.limits[label] {
+		collector.overflows[label]++
+		return otherValue
+	}
+
+	values[value] = true
+
+	return value
+}
+
+func observe(histograms map[routeKey]*histogram, key routeKey, buckets []float64, value float64) {
+	current := histograms[key]
+	if current == nil {
+		current = &histogram{counts: make([]int, len(buckets))}
+		histograms[key] = current
+	}
+
+	for ind, bound := range buckets {
+		if value <= bound {
+			current.counts[ind]++
+		}
+	}
+
+	current.count++
+	current.sum += value
+}
+
+func (collector *Collector) WriteTo(writer io.Writer) (int64, error) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	var builder strings.Builder
+
+	collector.writeRequests(&builder)
+	writeHistograms(&builder, "nginx_http_response_size_bytes", "Размер ответа в байтах.", collector.sizes, sizeBuckets)
+	writeHistograms(&builder, "nginx_http_request_duration_seconds", "Время обработки запроса ($request_time) в секундах.",
+		collector.latencies, latencyBuckets)
+	collector.writeOverflows(&builder)
+
+	fmt.Fprintln(&builder, "# HELP nginx_log_exporter_errors_total Ошибки чтения и разбора логов.")
+	fmt.Fprintln(&builder, "# TYPE nginx_log_exporter_errors_total counter")
+	fmt.Fprintf(&builder, "nginx_log_exporter_errors_total %d\n", collector.errors)
+
+	size, err := io.WriteString(writer, builder.String())
+
+	return int64(size), err
+}
+
+func (collector *Collector) writeRequests(writer io.Writer) {
+	keys := make([]requestKey, 0, len(collector.requests))
+	for key := range collector.requests {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+
+		return keys[i].status < keys[j].status
+	})
+
+	fmt.Fprintln(writer, "# HELP nginx_http_requests_total Количество запросов.")
+	fmt.Fprintln(writer, "# TYPE nginx_http_requests_total counter")
+
+	for _, key := range keys {
+		fmt.Fprintf(writer, "nginx_http_requests_total{method=\"%s\",status=\"%s\",route=\"%s\"} %d\n",
+			escape(key.method), escape(key.status), escape(key.route), collector.requests[key])
+	}
+}
+
+func writeHistograms(writer io.Writer, name, help string, histograms map[routeKey]*histogram, buckets []float64) {
+	keys := make([]routeKey, 0, len(histograms))
+	for key := range histograms {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+
+		return keys[i].method < keys[j].method
+	})
+
+	fmt.Fprintf(writer, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(writer, "# TYPE %s histogram\n", name)
+
+	for _, key := range keys {
+		current := histograms[key]
+		labels := fmt.Sprintf("method=\"%s\",route=\"%s\"", escape(key.method), escape(key.route))
+
+		for ind, bound := range buckets {
+			fmt.Fprintf(writer, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(bound), current.counts[ind])
+		}
+
+		fmt.Fprintf(writer, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, current.count)
+		fmt.Fprintf(writer, "%s_sum{%s} %s\n", name, labels, formatFloat(current.sum))
+		fmt.Fprintf(writer, "%s_count{%s} %d\n", name, labels, current.count)
+	}
+}
+
+func (collector *Collector) writeOverflows(writer io.Writer) {
+	fmt.Fprintln(writer, "# HELP nginx_log_exporter_label_overflow_total Значения меток, заменённые на \"other\" из-за ограничения.")
+	fmt.Fprintln(writer, "# TYPE nginx_log_exporter_label_overflow_total counter")
+
+	for _, label := range []string{domain.LabelMethod, domain.LabelStatus, domain.LabelRoute} {
+		fmt.Fprintf(writer, "nginx_log_exporter_label_overflow_total{label=\"%s\"} %d\n", label, collector.overflows[label])
+	}
+}
+
+func escape(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRecords() []domain.LogRecord {
+	return []domain.LogRecord{
+		{Method: "GET", URL: "/users?page=1", Status: 200, BodyBytesSent: 512, RequestTime: 20 * time.Millisecond, HasRequestTime: true},
+		{Method: "GET", URL: "/users?page=2", Status: 200, BodyBytesSent: 2048, RequestTime: 300 * time.Millisecond, HasRequestTime: true},
+		{Method: "POST", URL: "/login", Status: 401, BodyBytesSent: 64},
+		{Method: "GET", URL: "/orders", Status: 500, BodyBytesSent: 0},
+		{Method: "GET", URL: "/cart\"", Status: 200, BodyBytesSent: 10},
+	}
+}
+
+func writeMetrics(t *testing.T, collector *Collector) string {
+	t.Helper()
+
+	var builder strings.Builder
+
+	size, err := collector.WriteTo(&builder)
+	require.NoError(t, err)
+	assert.Equal(t, int64(builder.Len()), size)
+
+	return builder.String()
+}
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector(map[string]int{domain.LabelMethod: 10, domain.LabelStatus: 10, domain.LabelRoute: 10})
+	collector.Observe(createRecords())
+
+	output := writeMetrics(t, collector)
+
+	assert.Contains(t, output, "# TYPE nginx_http_requests_total counter\n")
+	assert.Contains(t, output, `nginx_http_requests_total{method="GET",status="200",route="/users"} 2`+"\n")
+	assert.Contains(t, output, `nginx_http_requests_total{method="POST",status="401",route="/login"} 1`+"\n")
+	assert.Contains(t, output, `nginx_http_requests_total{method="GET",status="200",route="/cart\""} 1`+"\n")
+
+	assert.Contains(t, output, `nginx_http_response_size_bytes_bucket{method="GET",route="/users",le="1024"} 1`+"\n")
+	assert.Contains(t, output, `nginx_http_response_size_bytes_bucket{method="GET",route="/users",le="10240"} 2`+"\n")
+	assert.Contains(t, output, `nginx_http_response_size_bytes_sum{method="GET",route="/users"} 2560`+"\n")
+
+	assert.Contains(t, output, `nginx_http_request_duration_seconds_bucket{method="GET",route="/users",le="0.025"} 1`+"\n")
+	assert.Contains(t, output, `nginx_http_request_duration_seconds_bucket{method="GET",route="/users",le="+Inf"} 2`+"\n")
+	assert.Contains(t, output, `nginx_http_request_duration_seconds_count{method="GET",route="/users"} 2`+"\n")
+	assert.NotContains(t, output, `nginx_http_request_duration_seconds_count{method="POST"`,
+		"Записи без request_time не попадают в гистограмму")
+}
+
+func TestCollector_LabelLimits(t *testing.T) {
+	collector := NewCollector(map[string]int{domain.LabelMethod: 10, domain.LabelStatus: 2, domain.LabelRoute: 2})
+	collector.Observe(createRecords())
+
+	output := writeMetrics(t, collector)
+
+	assert.Contains(t, output, `nginx_http_requests_total{method="GET",status="other",route="other"} 1`+"\n")
+	assert.Contains(t, output, `nginx_http_requests_total{method="GET",status="200",route="other"} 1`+"\n")
+	assert.Contains(t, output, `nginx_log_exporter_label_overflow_total{label="route"} 2`+"\n")
+	assert.Contains(t, output, `nginx_log_exporter_label_overflow_total{label="status"} 1`+"\n")
+	assert.NotContains(t, output, `route="/orders"`)
+}
@@ -0,0 +1,192 @@
+package normalizer
+
+import (
+	domain "analyzer/internal/domain"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type placeholder struct {
+	name    string
+	pattern *regexp.Regexp
+	inParts bool
+}
+
+type URLNormalizer struct {
+	placeholders []placeholder
+}
+
+func NewURLNormalizer() *URLNormalizer {
+	return &URLNormalizer{
+		placeholders: []placeholder{
+			{name: "{id}", pattern: regexp.MustCompile(`^\d+$`)},
+			{name: "{uuid}", pattern: regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)},
+			{name: "{hash}", pattern: regexp.MustCompile(`^[0-9a-fA-F]{16,}$`), inParts: true},
+		},
+	}
+}
+
+func (normalizer *URLNormalizer) Normalize(records []domain.LogRecord, config *domain.Config) []domain.LogRecord {
+	if config.URLOptions.IsEmpty() {
+		return records
+	}
+
+	normalized := make(map[string]string)
+
+	for ind := range records {
+		record := &records[ind]
+
+		value, exists := normalized[record.URL]
+		if !exists {
+			value = normalizer.NormalizeURL(record.URL, &config.URLOptions)
+			normalized[record.URL] = value
+		}
+
+		record.URL = value
+	}
+
+	return records
+}
+
+func (normalizer *URLNormalizer) NormalizeURL(rawURL string, options *domain.URLOptions) string {
+	path, query, hasQuery := strings.Cut(rawURL, "?")
+
+	if options.Decode {
+		path = decodePath(path)
+	}
+
+	if options.Lowercase {
+		path = strings.ToLower(path)
+	}
+
+	if hasQuery {
+		query = normalizer.filterQuery(query, options)
+	}
+
+	path = normalizer.applyRoutes(path, options)
+
+	if query == "" {
+		return path
+	}
+
+	return path + "?" + query
+}
+
+func (normalizer *URLNormalizer) filterQuery(query string, options *domain.URLOptions) string {
+	if slices.Contains(options.StripQuery, domain.StripAllQuery) {
+		return ""
+	}
+
+	params := make([]string, 0)
+
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(param, "=")
+
+		if options.Decode {
+			key = decodeQuery(key)
+			value = decodeQuery(value)
+		}
+
+		if options.Lowercase {
+			key = strings.ToLower(key)
+		}
+
+		if !keepParam(key, options) {
+			continue
+		}
+
+		if options.Decode {
+			key = url.QueryEscape(key)
+			value = url.QueryEscape(value)
+		}
+
+		if hasValue {
+			params = append(params, key+"="+value)
+		} else {
+			params = append(params, key)
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+func (normalizer *URLNormalizer) applyRoutes(path string, options *domain.URLOptions) string {
+	for _, route := range options.Routes {
+		if route.Pattern.MatchString(path) {
+			return route.Template
+		}
+	}
+
+	if !options.CollapseIDs {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = normalizer.collapseSegment(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func (normalizer *URLNormalizer) collapseSegment(segment string) string {
+	for _, placeholder := range normalizer.placeholders {
+		if placeholder.pattern.MatchString(segment) {
+			return placeholder.name
+		}
+	}
+
+	parts := strings.Split(segment, ".")
+	if len(parts) == 1 {
+		return segment
+	}
+
+	for i, part := range parts {
+		for _, placeholder := range normalizer.placeholders {
+			if placeholder.inParts && placeholder.pattern.MatchString(part) {
+				parts[i] = placeholder.name
+				break
+			}
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func keepParam(key string, options *domain.URLOptions) bool {
+	if len(options.KeepQuery) > 0 {
+		return containsFold(options.KeepQuery, key)
+	}
+
+	return !containsFold(options.StripQuery, key)
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(item string) bool {
+		return strings.EqualFold(item, value)
+	})
+}
+
+func decodePath(path string) string {
+	decoded, err := url.PathUnescape(path)
+	if err != nil {
+		return path
+	}
+
+	return decoded
+}
+
+func decodeQuery(value string) string {
+	decoded, err := url.QueryUnescape(value)
+	if err != nil {
+		return value
+	}
+
+	return decoded
+}
@@ -0,0 +1,86 @@
+package normalizer
+
+import (
+	"testing"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	normalizer := NewURLNormalizer()
+
+	t.Run("CollapseIDs", func(t *testing.T) {
+		options := &domain.URLOptions{CollapseIDs: true}
+
+		assert.Equal(t, "/users/{id}", normalizer.NormalizeURL("/users/123", options))
+		assert.Equal(t, "/orders/{uuid}/items",
+			normalizer.NormalizeURL("/orders/3f2b8c1e-9a4d-4c7b-8e2f-1a2b3c4d5e6f/items", options))
+		assert.Equal(t, "/static/app.{hash}.js",
+			normalizer.NormalizeURL("/static/app.d41d8cd98f00b204e9800998ecf8427e.js", options))
+		assert.Equal(t, "/api/v1.2/users", normalizer.NormalizeURL("/api/v1.2/users", options))
+		assert.Equal(t, "/files/{hash}", normalizer.NormalizeURL("/files/d41d8cd98f00b204e9800998ecf8427e", options))
+	})
+
+	t.Run("LowercaseAndDecode", func(t *testing.T) {
+		options := &domain.URLOptions{Lowercase: true, Decode: true}
+
+		assert.Equal(t, "/search/hello world?q=a+b", normalizer.NormalizeURL("/Search/Hello%20World?Q=a%20b", options))
+		assert.Equal(t, "/search?q=Tom+%26+Jerry&sort=Name", normalizer.NormalizeURL("/search?Q=Tom+%26+Jerry&SORT=Name", options))
+		assert.Equal(t, "/calc?expr=a%3Db", normalizer.NormalizeURL("/calc?expr=a%3db", options))
+		assert.NotEqual(t, normalizer.NormalizeURL("/search?q=a%26b", options), normalizer.NormalizeURL("/search?q=a&b", options))
+	})
+
+	t.Run("StripQuery", func(t *testing.T) {
+		options := &domain.URLOptions{StripQuery: []string{"utm_source", "utm_medium"}}
+
+		assert.Equal(t, "/page?id=1", normalizer.NormalizeURL("/page?utm_source=x&id=1&utm_medium=y", options))
+	})
+
+	t.Run("StripAllQuery", func(t *testing.T) {
+		options := &domain.URLOptions{StripQuery: []string{domain.StripAllQuery}}
+
+		assert.Equal(t, "/page", normalizer.NormalizeURL("/page?utm_source=x&id=1", options))
+	})
+
+	t.Run("KeepQuery", func(t *testing.T) {
+		options := &domain.URLOptions{KeepQuery: []string{"page"}}
+
+		assert.Equal(t, "/list?page=2", normalizer.NormalizeURL("/list?session=abc&page=2", options))
+	})
+
+	t.Run("Routes", func(t *testing.T) {
+		route, err := domain.NewRoute("/api/{version}/users/{name}")
+		require.NoError(t, err)
+
+		wildcard, err := domain.NewRoute("/static/*")
+		require.NoError(t, err)
+
+		options := &domain.URLOptions{CollapseIDs: true, Routes: []domain.Route{route, wildcard}}
+
+		assert.Equal(t, "/api/{version}/users/{name}", normalizer.NormalizeURL("/api/v2/users/john", options))
+		assert.Equal(t, "/static/*", normalizer.NormalizeURL("/static/css/main.css", options))
+		assert.Equal(t, "/posts/{id}", normalizer.NormalizeURL("/posts/42", options))
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	normalizer := NewURLNormalizer()
+	records := []domain.LogRecord{
+		{URL: "/users/123"},
+		{URL: "/users/456?ref=mail"},
+		{URL: "/about"},
+	}
+	config := &domain.Config{
+		URLOptions: domain.URLOptions{CollapseIDs: true, StripQuery: []string{domain.StripAllQuery}},
+	}
+
+	normalized := normalizer.Normalize(records, config)
+
+	require.Len(t, normalized, 3)
+	assert.Equal(t, "/users/{id}", normalized[0].URL)
+	assert.Equal(t, "/users/{id}", normalized[1].URL)
+	assert.Equal(t, "/about", normalized[2].URL)
+}
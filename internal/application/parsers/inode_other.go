@@ -0,0 +1,9 @@
+//go:build !unix
+
+package parsers
+
+import "os"
+
+func fileInode(_ os.FileInfo) uint64 {
+	return 0
+}
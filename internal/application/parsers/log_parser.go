@@ -4,17 +4,32 @@ import (
 	domain "analyzer/internal/domain"
 	"bufio"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	defaultChunkSize = 4 << 20
+	defaultBatchSize = 100_000
+)
+
 type LogParser struct {
 	LogPattern *regexp.Regexp
+	ChunkSize  int64
+	BatchSize  int
+}
+
+type fileChunk struct {
+	path  string
+	start int64
+	end   int64
 }
 
 func NewLogParser() *LogParser {
@@ -22,32 +37,131 @@ func NewLogParser() *LogParser {
 		LogPattern: regexp.MustCompile(`(?P<remote_addr>\S+) - (?P<remote_user>\S+) ` +
 			`\[(?P<time_local>\S+\s\S+)\] "(?P<request>[^"]*)" ` +
 			`(?P<status>\d+) (?P<body_bytes_sent>\d+) ` +
-			`"(?P<referer>[^"]*)" "(?P<user_agent>[^"]*)"`),
+			`"(?P<referer>[^"]*)" "(?P<user_agent>[^"]*)"` +
+			`(?: "(?P<forwarded_for>[^"]*)")?(?: "(?P<real_ip>[^"]*)")?(?: (?P<request_time>\d+(?:\.\d+)?))?`),
+		ChunkSize: defaultChunkSize,
+		BatchSize: defaultBatchSize,
 	}
 }
 
 func (parser *LogParser) Parse(config *domain.Config) ([]domain.LogRecord, error) {
-	logs := make([]string, 0)
+	if config.TypePath == "url" {
+		logs, offsets, err := parser.getLogsFromURL(config.Path)
+		if err != nil {
+			return nil, err
+		}
 
-	var err error
+		logRecords, err := parser.parseLines(logs, config.Workers)
+		if err != nil {
+			return nil, err
+		}
 
-	switch config.TypePath {
-	case "url":
-		logs, err = parser.getLogsFromURL(config.Path)
-	case "local":
-		logs, err = parser.getLogsFromLocal(config.Path)
+		return withSource(logRecords, config.Path, offsets), nil
 	}
 
+	chunks, err := parser.splitFiles(config.Path)
 	if err != nil {
 		return nil, err
 	}
 
-	logRecords, err := parser.parseLogs(logs)
+	return runParallel(len(chunks), max(config.Workers, 1), func(ind int) ([]domain.LogRecord, error) {
+		logs, offsets, err := readChunk(chunks[ind])
+		if err != nil {
+			return nil, err
+		}
+
+		logRecords, err := parser.parseLogs(logs)
+		if err != nil {
+			return nil, err
+		}
+
+		return withSource(logRecords, sourceName(chunks[ind].path), offsets), nil
+	})
+}
+
+func (parser *LogParser) ParseReader(reader io.Reader, config *domain.Config) ([]domain.LogRecord, error) {
+	scanner := bufio.NewScanner(reader)
+	logs := make([]string, 0)
+
+	for scanner.Scan() {
+		logs = append(logs, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать логи: %v", err)
+	}
+
+	return parser.parseLines(logs, config.Workers)
+}
+
+func (parser *LogParser) ParseBatches(config *domain.Config, handle func(records []domain.LogRecord) error) error {
+	if config.TypePath == "url" {
+		body, err := openURL(config.Path)
+		if err != nil {
+			return err
+		}
+
+		defer body.Close()
+
+		return parser.parseBatches(body, config.Path, config.Workers, handle)
+	}
+
+	matches, _ := filepath.Glob(config.Path)
+
+	for _, path := range matches {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("не удалось прочитать файл %s: %v", path, err)
+		}
+
+		err = parser.parseBatches(file, sourceName(path), config.Workers, handle)
+		file.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (parser *LogParser) parseBatches(reader io.Reader, source string, workers int,
+	handle func(records []domain.LogRecord) error) error {
+	size := max(parser.BatchSize, 1)
+	logs := make([]string, 0, size)
+	offsets := make([]int64, 0, size)
+
+	flush := func() error {
+		logRecords, err := parser.parseLines(logs, workers)
+		if err != nil {
+			return err
+		}
+
+		logRecords = withSource(logRecords, source, offsets)
+		logs, offsets = logs[:0], offsets[:0]
+
+		return handle(logRecords)
+	}
+
+	err := readLines(reader, func(line string, offset int64) error {
+		logs = append(logs, line)
+		offsets = append(offsets, offset)
+
+		if len(logs) < size {
+			return nil
+		}
+
+		return flush()
+	})
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if len(logs) == 0 {
+		return nil
 	}
 
-	return logRecords, err
+	return flush()
 }
 
 func (parser *LogParser) parseLogs(logs []string) ([]domain.LogRecord, error) {
@@ -65,7 +179,255 @@ func (parser *LogParser) parseLogs(logs []string) ([]domain.LogRecord, error) {
 	return logRecords, nil
 }
 
-func (parser *LogParser) getLogsFromURL(url string) ([]string, error) {
+func (parser *LogParser) ParseLines(logs []string) ([]domain.LogRecord, []error) {
+	logRecords := make([]domain.LogRecord, 0, len(logs))
+	errs := make([]error, 0)
+
+	for _, log := range logs {
+		logRecord, err := parser.parseLogLine(log)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		logRecords = append(logRecords, logRecord)
+	}
+
+	return logRecords, errs
+}
+
+func (parser *LogParser) ParseIncremental(config *domain.Config,
+	files []domain.FileCheckpoint) ([]domain.LogRecord, []domain.FileCheckpoint, []error, error) {
+	matches, _ := filepath.Glob(config.Path)
+	positions := make([]domain.FileCheckpoint, 0, len(matches))
+	logs := make([]string, 0)
+
+	for _, path := range matches {
+		lines, position, err := readIncrement(path, files)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		logs = append(logs, lines...)
+		positions = append(positions, position)
+	}
+
+	logRecords, errs := parser.skipInvalid(logs, config.Workers)
+
+	return logRecords, positions, errs, nil
+}
+
+func (parser *LogParser) skipInvalid(logs []string, workers int) ([]domain.LogRecord, []error) {
+	if workers <= 1 {
+		return parser.ParseLines(logs)
+	}
+
+	size := max((len(logs)+workers-1)/workers, 1)
+	jobs := (len(logs) + size - 1) / size
+	chunkErrs := make([][]error, jobs)
+
+	logRecords, _ := runParallel(jobs, workers, func(ind int) ([]domain.LogRecord, error) {
+		chunk, errs := parser.ParseLines(logs[ind*size : min((ind+1)*size, len(logs))])
+		chunkErrs[ind] = errs
+
+		return chunk, nil
+	})
+
+	errs := make([]error, 0)
+	for _, chunk := range chunkErrs {
+		errs = append(errs, chunk...)
+	}
+
+	return logRecords, errs
+}
+
+func (parser *LogParser) parseLines(logs []string, workers int) ([]domain.LogRecord, error) {
+	if workers <= 1 {
+		return parser.parseLogs(logs)
+	}
+
+	size := max((len(logs)+workers-1)/workers, 1)
+
+	return runParallel((len(logs)+size-1)/size, workers, func(ind int) ([]domain.LogRecord, error) {
+		return parser.parseLogs(logs[ind*size : min((ind+1)*size, len(logs))])
+	})
+}
+
+func readIncrement(path string, files []domain.FileCheckpoint) ([]string, domain.FileCheckpoint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, domain.FileCheckpoint{}, fmt.Errorf("не удалось прочитать файл %s: %v", path, err)
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, domain.FileCheckpoint{}, fmt.Errorf("не удалось прочитать файл %s: %v", path, err)
+	}
+
+	position := domain.FileCheckpoint{Path: path, Inode: fileInode(info), Size: info.Size()}
+	position.Offset = resumeOffset(files, position)
+
+	if _, err := file.Seek(position.Offset, io.SeekStart); err != nil {
+		return nil, domain.FileCheckpoint{}, fmt.Errorf("не удалось прочитать файл %s: %v", path, err)
+	}
+
+	reader := bufio.NewReader(io.LimitReader(file, position.Size-position.Offset))
+	logs := make([]string, 0)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, domain.FileCheckpoint{}, fmt.Errorf("не удалось прочитать файл %s: %v", path, err)
+		}
+
+		position.Offset += int64(len(line))
+		logs = append(logs, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+	}
+
+	return logs, position, nil
+}
+
+func resumeOffset(files []domain.FileCheckpoint, current domain.FileCheckpoint) int64 {
+	for _, previous := range files {
+		sameFile := previous.Path == current.Path
+		if current.Inode != 0 {
+			sameFile = previous.Inode == current.Inode
+		}
+
+		if !sameFile {
+			continue
+		}
+
+		if current.Size < previous.Size || current.Size < previous.Offset {
+			return 0
+		}
+
+		return previous.Offset
+	}
+
+	return 0
+}
+
+func (parser *LogParser) splitFiles(path string) ([]fileChunk, error) {
+	matches, _ := filepath.Glob(path)
+	chunks := make([]fileChunk, 0, len(matches))
+	chunkSize := max(parser.ChunkSize, 1)
+
+	for _, file := range matches {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать файл %s: %v", file, err)
+		}
+
+		for start := int64(0); start < info.Size(); start += chunkSize {
+			chunks = append(chunks, fileChunk{path: file, start: start, end: min(start+chunkSize, info.Size())})
+		}
+	}
+
+	return chunks, nil
+}
+
+func readChunk(chunk fileChunk) ([]string, []int64, error) {
+	file, err := os.Open(chunk.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("не удалось прочитать файл %s: %v", chunk.path, err)
+	}
+
+	defer file.Close()
+
+	offset := chunk.start
+
+	if offset > 0 {
+		previous := make([]byte, 1)
+
+		if _, err := file.ReadAt(previous, offset-1); err != nil {
+			return nil, nil, fmt.Errorf("не удалось прочитать файл %s: %v", chunk.path, err)
+		}
+
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return nil, nil, fmt.Errorf("не удалось прочитать файл %s: %v", chunk.path, err)
+		}
+
+		if previous[0] != '\n' {
+			offset = -1
+		}
+	}
+
+	reader := bufio.NewReader(file)
+	logs := make([]string, 0)
+	offsets := make([]int64, 0)
+
+	for offset < chunk.end {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, nil, fmt.Errorf("не удалось прочитать файл %s: %v", chunk.path, err)
+		}
+
+		if offset >= 0 && line != "" {
+			logs = append(logs, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+			offsets = append(offsets, offset)
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if offset < 0 {
+			offset = chunk.start
+		}
+
+		offset += int64(len(line))
+	}
+
+	return logs, offsets, nil
+}
+
+func runParallel(jobs, workers int, parse func(ind int) ([]domain.LogRecord, error)) ([]domain.LogRecord, error) {
+	results := make([][]domain.LogRecord, jobs)
+	errs := make([]error, jobs)
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(workers, jobs) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for ind := range queue {
+				results[ind], errs[ind] = parse(ind)
+			}
+		}()
+	}
+
+	for ind := range jobs {
+		queue <- ind
+	}
+
+	close(queue)
+	wg.Wait()
+
+	logRecords := make([]domain.LogRecord, 0)
+
+	for ind := range jobs {
+		if errs[ind] != nil {
+			return nil, errs[ind]
+		}
+
+		logRecords = append(logRecords, results[ind]...)
+	}
+
+	return logRecords, nil
+}
+
+func openURL(url string) (io.ReadCloser, error) {
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
@@ -75,43 +437,77 @@ func (parser *LogParser) getLogsFromURL(url string) ([]string, error) {
 		return nil, fmt.Errorf("не удалось выполнить запрос по URL %s: %v", url, err)
 	}
 
-	defer response.Body.Close()
-
 	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
 		return nil, fmt.Errorf("не удалось получить файл: %s", response.Status)
 	}
 
-	scanner := bufio.NewScanner(response.Body)
+	return response.Body, nil
+}
+
+func (parser *LogParser) getLogsFromURL(url string) ([]string, []int64, error) {
+	body, err := openURL(url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer body.Close()
+
 	logs := make([]string, 0)
+	offsets := make([]int64, 0)
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	err = readLines(body, func(line string, offset int64) error {
 		logs = append(logs, line)
+		offsets = append(offsets, offset)
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return logs, nil
+	return logs, offsets, nil
 }
 
-func (parser *LogParser) getLogsFromLocal(path string) ([]string, error) {
-	matches, _ := filepath.Glob(path)
-	logs := make([]string, 0)
+func readLines(reader io.Reader, each func(line string, offset int64) error) error {
+	buffered := bufio.NewReader(reader)
+	offset := int64(0)
 
-	for _, file := range matches {
-		file, err := os.Open(file)
-		if err != nil {
-			return nil, fmt.Errorf("не удалось прочитать файл %s: %v", file.Name(), err)
+	for {
+		line, err := buffered.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("не удалось прочитать логи: %v", err)
 		}
 
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			log := scanner.Text()
-			logs = append(logs, log)
+		if line != "" {
+			if err := each(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), offset); err != nil {
+				return err
+			}
 		}
 
-		file.Close()
+		if err == io.EOF {
+			return nil
+		}
+
+		offset += int64(len(line))
+	}
+}
+
+func withSource(logRecords []domain.LogRecord, source string, offsets []int64) []domain.LogRecord {
+	for ind := range logRecords {
+		logRecords[ind].Source = source
+		logRecords[ind].Offset = offsets[ind]
 	}
 
-	return logs, nil
+	return logRecords
+}
+
+func sourceName(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		return absolute
+	}
+
+	return path
 }
 
 func (parser *LogParser) parseLogLine(line string) (domain.LogRecord, error) {
@@ -124,10 +520,17 @@ func (parser *LogParser) parseLogLine(line string) (domain.LogRecord, error) {
 	remoteUser := matches[2]
 	timeLocalStr := matches[3]
 	request := strings.Split(matches[4], " ")
+	if len(request) != 3 {
+		return domain.LogRecord{}, fmt.Errorf("не удалось разобрать запрос: %s", matches[4])
+	}
+
 	statusStr := matches[5]
 	bodyBytesSentStr := matches[6]
 	referer := matches[7]
 	userAgent := matches[8]
+	forwardedFor := optionalField(matches[9])
+	realIP := optionalField(matches[10])
+	requestTimeStr := matches[11]
 
 	timeLocal, err := time.Parse("02/Jan/2006:15:04:05 +0000", timeLocalStr)
 	if err != nil {
@@ -144,6 +547,17 @@ func (parser *LogParser) parseLogLine(line string) (domain.LogRecord, error) {
 		return domain.LogRecord{}, fmt.Errorf("не удалось преобразовать body_bytes_sent: %v", err)
 	}
 
+	var requestTime time.Duration
+
+	if requestTimeStr != "" {
+		seconds, err := strconv.ParseFloat(requestTimeStr, 64)
+		if err != nil {
+			return domain.LogRecord{}, fmt.Errorf("не удалось преобразовать request_time: %v", err)
+		}
+
+		requestTime = time.Duration(seconds * float64(time.Second))
+	}
+
 	return domain.LogRecord{
 		RemoteAddr:      remoteAddr,
 		RemoteUser:      remoteUser,
@@ -155,5 +569,17 @@ func (parser *LogParser) parseLogLine(line string) (domain.LogRecord, error) {
 		BodyBytesSent:   bodyBytesSent,
 		Referer:         referer,
 		UserAgent:       userAgent,
+		ForwardedFor:    forwardedFor,
+		RealIP:          realIP,
+		RequestTime:     requestTime,
+		HasRequestTime:  requestTimeStr != "",
 	}, nil
 }
+
+func optionalField(value string) string {
+	if value == "-" {
+		return ""
+	}
+
+	return value
+}
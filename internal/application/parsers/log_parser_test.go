@@ -2,6 +2,10 @@ package parsers
 
 import (
 	"analyzer/internal/domain"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,12 +36,58 @@ func TestParseLogLine_ValidLine(t *testing.T) {
 	assert.Equal(t, expected, logRecord)
 }
 
+func TestParseLogLine_ForwardedFields(t *testing.T) {
+	parser := NewLogParser()
+
+	t.Run("ForwardedForAndRealIP", func(t *testing.T) {
+		line := `10.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "GET / HTTP/1.1" 200 1024 "-" "Mozilla/5.0" ` +
+			`"203.0.113.7, 10.0.0.2" "203.0.113.7"`
+
+		logRecord, err := parser.parseLogLine(line)
+
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.1", logRecord.RemoteAddr)
+		assert.Equal(t, "Mozilla/5.0", logRecord.UserAgent)
+		assert.Equal(t, "203.0.113.7, 10.0.0.2", logRecord.ForwardedFor)
+		assert.Equal(t, "203.0.113.7", logRecord.RealIP)
+	})
+
+	t.Run("EmptyForwardedFor", func(t *testing.T) {
+		line := `10.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "GET / HTTP/1.1" 200 1024 "-" "Mozilla/5.0" "-"`
+
+		logRecord, err := parser.parseLogLine(line)
+
+		require.NoError(t, err)
+		assert.Empty(t, logRecord.ForwardedFor)
+		assert.Empty(t, logRecord.RealIP)
+	})
+
+	t.Run("RequestTime", func(t *testing.T) {
+		line := `10.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "GET / HTTP/1.1" 200 1024 "-" "Mozilla/5.0" "-" "-" 0.250`
+
+		logRecord, err := parser.parseLogLine(line)
+
+		require.NoError(t, err)
+		assert.True(t, logRecord.HasRequestTime)
+		assert.Equal(t, 250*time.Millisecond, logRecord.RequestTime)
+
+		logRecord, err = parser.parseLogLine(`10.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "GET / HTTP/1.1" 200 1024 "-" "Mozilla/5.0" 0.000`)
+
+		require.NoError(t, err)
+		assert.True(t, logRecord.HasRequestTime)
+		assert.Zero(t, logRecord.RequestTime)
+	})
+}
+
 func TestParseLogLine_InvalidLine(t *testing.T) {
 	parser := NewLogParser()
 	line := `Invalid log line format`
 
 	_, err := parser.parseLogLine(line)
 	assert.Error(t, err)
+
+	_, err = parser.parseLogLine(`10.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "-" 400 0 "-" "-"`)
+	assert.Error(t, err)
 }
 
 func TestParseLogs_ValidLogs(t *testing.T) {
@@ -52,3 +102,185 @@ func TestParseLogs_ValidLogs(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, logRecords, 3)
 }
+
+func TestParseLines(t *testing.T) {
+	parser := NewLogParser()
+	logLines := []string{
+		`127.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "GET /index.html HTTP/1.1" 200 1024 "http://example.com" "Mozilla/5.0"`,
+		`Invalid log line format`,
+		`127.0.0.1 - - [12/Oct/2023:16:32:00 +0000] "PUT /image.jpg HTTP/1.1" 200 256 "http://example.com" "Mozilla*"`,
+	}
+
+	logRecords, errs := parser.ParseLines(logLines)
+
+	assert.Len(t, logRecords, 2)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/image.jpg", logRecords[1].URL)
+}
+
+func TestParse_Workers(t *testing.T) {
+	dir := t.TempDir()
+
+	for file := range 3 {
+		lines := make([]string, 0)
+
+		for i := range 200 {
+			lines = append(lines, fmt.Sprintf(`10.0.%d.%d - - [12/Oct/2023:14:%02d:%02d +0000] "GET /page/%d HTTP/1.1" 200 %d "-" "Mozilla/5.0"`,
+				file, i%250, i/60, i%60, i, i*10))
+		}
+
+		content := strings.Join(lines, "\r\n")
+		if file != 2 {
+			content += "\n"
+		}
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("access%d.log", file)), []byte(content), 0o600))
+	}
+
+	parser := NewLogParser()
+	parser.ChunkSize = 1000
+
+	config := &domain.Config{Path: filepath.Join(dir, "*.log"), TypePath: "local"}
+
+	sequential, err := parser.Parse(config)
+	require.NoError(t, err)
+
+	config.Workers = 4
+
+	parallel, err := parser.Parse(config)
+	require.NoError(t, err)
+
+	assert.Len(t, parallel, 600)
+	assert.Equal(t, sequential, parallel)
+}
+
+func TestParseBatches(t *testing.T) {
+	dir := t.TempDir()
+
+	for file := range 2 {
+		lines := make([]string, 0)
+
+		for i := range 250 {
+			lines = append(lines, fmt.Sprintf(`10.0.%d.%d - - [12/Oct/2023:14:%02d:%02d +0000] "GET /page/%d HTTP/1.1" 200 %d "-" "Mozilla/5.0"`,
+				file, i%250, i/60, i%60, i, i*10))
+		}
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("access%d.log", file)), []byte(strings.Join(lines, "\n")), 0o600))
+	}
+
+	parser := NewLogParser()
+	parser.BatchSize = 100
+
+	config := &domain.Config{Path: filepath.Join(dir, "*.log"), TypePath: "local", Workers: 2}
+
+	expected, err := parser.Parse(config)
+	require.NoError(t, err)
+
+	sizes := make([]int, 0)
+	records := make([]domain.LogRecord, 0)
+
+	err = parser.ParseBatches(config, func(batch []domain.LogRecord) error {
+		sizes = append(sizes, len(batch))
+		records = append(records, batch...)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{100, 100, 50, 100, 100, 50}, sizes)
+	assert.Equal(t, expected, records)
+
+	err = parser.ParseBatches(config, func([]domain.LogRecord) error {
+		return fmt.Errorf("остановка")
+	})
+	assert.ErrorContains(t, err, "остановка")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "access2.log"), []byte("bad line\n"), 0o600))
+
+	err = parser.ParseBatches(config, func([]domain.LogRecord) error { return nil })
+	assert.Error(t, err, "Ожидалось, что выкинется ошибка для неразобранной строки")
+}
+
+func TestParseIncremental(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	parser := NewLogParser()
+	config := &domain.Config{Path: filepath.Join(dir, "access.log*"), TypePath: "local"}
+
+	logLine := func(page int) string {
+		return fmt.Sprintf(`127.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "GET /page/%d HTTP/1.1" 200 512 "-" "Mozilla/5.0"`, page)
+	}
+
+	appendLines := func(path, content string) {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+
+		_, err = file.WriteString(content)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+
+	urls := func(records []domain.LogRecord) []string {
+		result := make([]string, 0, len(records))
+		for _, record := range records {
+			result = append(result, record.URL)
+		}
+
+		return result
+	}
+
+	partial := logLine(3)
+	appendLines(path, logLine(1)+"\n"+logLine(2)+"\n"+partial[:20])
+
+	records, files, errs, err := parser.ParseIncremental(config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/page/1", "/page/2"}, urls(records))
+	require.Len(t, files, 1)
+	assert.Equal(t, int64(2*(len(logLine(1))+1)), files[0].Offset)
+
+	t.Run("Appended", func(t *testing.T) {
+		appendLines(path, partial[20:]+"\n"+logLine(4)+"\n")
+
+		records, files, errs, err = parser.ParseIncremental(config, files)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/page/3", "/page/4"}, urls(records))
+		assert.Equal(t, files[0].Size, files[0].Offset)
+	})
+
+	t.Run("Rotated", func(t *testing.T) {
+		appendLines(path, logLine(5)+"\n")
+		require.NoError(t, os.Rename(path, path+".1"))
+		appendLines(path, logLine(6)+"\n")
+
+		records, files, errs, err = parser.ParseIncremental(config, files)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"/page/5", "/page/6"}, urls(records))
+		require.NoError(t, os.Remove(path+".1"))
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		require.NoError(t, os.Truncate(path, 0))
+
+		records, files, errs, err = parser.ParseIncremental(config, files)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+		assert.Zero(t, files[0].Offset)
+
+		appendLines(path, logLine(7)+"\n")
+
+		records, files, errs, err = parser.ParseIncremental(config, files)
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+		assert.Equal(t, []string{"/page/7"}, urls(records))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		appendLines(path, "bad line\n"+logLine(8)+"\n")
+
+		records, files, errs, err = parser.ParseIncremental(config, files)
+		require.NoError(t, err)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, []string{"/page/8"}, urls(records))
+		assert.Equal(t, files[0].Size, files[0].Offset)
+	})
+}
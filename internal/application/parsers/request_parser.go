@@ -18,12 +18,71 @@ func NewParserRequest() *SimpleParserRequest {
 func (parser *SimpleParserRequest) Parse(flags map[string]string) domain.Config {
 	config := domain.Config{}
 
-	err := config.AddPath(flags["path"])
+	err := config.AddSource(flags["path"])
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = config.AddFrom(flags["from"])
+	err = config.AddEmitState(flags["emit-state"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	parser.addOptions(&config, flags)
+
+	return config
+}
+
+func (parser *SimpleParserRequest) ParseServe(flags map[string]string) domain.Config {
+	config := domain.Config{}
+
+	err := config.AddServeSource(flags["path"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddSyslog(flags["syslog"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddListen(flags["listen"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddRequestTimeout(flags["timeout"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddMaxUpload(flags["max-upload"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddRefresh(flags["refresh"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddMaxRecords(flags["max-records"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddLabelLimits(flags["label-limits"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	parser.addOptions(&config, flags)
+
+	return config
+}
+
+func (parser *SimpleParserRequest) addOptions(config *domain.Config, flags map[string]string) {
+	err := config.AddFrom(flags["from"])
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -48,5 +107,230 @@ func (parser *SimpleParserRequest) Parse(flags map[string]string) domain.Config
 		log.Fatal(err)
 	}
 
+	err = config.AddNormalize(flags["normalize"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddStripQuery(flags["strip-query"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddKeepQuery(flags["keep-query"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddRoutes(flags["routes"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddUserAgentRules(flags["ua-rules"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddSecurityRules(flags["security-rules"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddTrustedProxies(flags["trusted-proxies"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddClientIPMode(flags["client-ip"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddProfileClient(flags["profile-client"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddApprox(flags["approx"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddWorkers(flags["workers"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnonymize(flags["anonymize"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnonymizeKey(flags["anonymize-key"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddRedactParams(flags["redact-params"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddCheckpoint(flags["checkpoint"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddStore(flags["store"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddRetention(flags["retention"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddDownsample(flags["downsample"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddExport(flags["export"], flags["output"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddExportMode(flags["mode"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddRowGroupSize(flags["row-group"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddPartition(flags["partition"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddGeoIPDatabases(flags["geoip-db"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddSiteHosts(flags["site-host"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddBucketSize(flags["bucket"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddSessionGap(flags["session-gap"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddFunnel(flags["funnel"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddFunnelWindow(flags["funnel-window"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnomalyThreshold(flags["anomaly-threshold"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnomalyWindow(flags["anomaly-window"])
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (parser *SimpleParserRequest) ParseMerge(paths []string, flags map[string]string) domain.Config {
+	config := domain.Config{}
+
+	err := config.AddStates(paths)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddEmitState(flags["emit-state"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddFormat(flags["format"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddSecurityRules(flags["security-rules"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnomalyThreshold(flags["anomaly-threshold"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnomalyWindow(flags["anomaly-window"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return config
+}
+
+func (parser *SimpleParserRequest) ParseQuery(flags map[string]string) domain.Config {
+	config := domain.Config{}
+
+	err := config.AddStore(flags["store"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddFrom(flags["from"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddTo(flags["to"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddFormat(flags["format"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddBucketSize(flags["bucket"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnomalyThreshold(flags["anomaly-threshold"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = config.AddAnomalyWindow(flags["anomaly-window"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return config
 }
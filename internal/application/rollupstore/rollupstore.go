@@ -0,0 +1,304 @@
+package rollupstore
+
+import (
+	domain "analyzer/internal/domain"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	minuteBucket = []byte("minute")
+	hourBucket   = []byte("hour")
+	sourceBucket = []byte("source")
+)
+
+type RollupStore struct {
+	timeout time.Duration
+}
+
+func NewRollupStore() *RollupStore {
+	return &RollupStore{timeout: 5 * time.Second}
+}
+
+func (store *RollupStore) Ingest(path string, rollups []domain.Rollup, files []domain.FileCheckpoint,
+	retention domain.RetentionOptions) error {
+	db, err := store.open(path, false)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		minutes, err := tx.CreateBucketIfNotExists(minuteBucket)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists(hourBucket); err != nil {
+			return err
+		}
+
+		for ind := range rollups {
+			if err := putRollup(minutes, &rollups[ind]); err != nil {
+				return err
+			}
+		}
+
+		if err := putSources(tx, files); err != nil {
+			return err
+		}
+
+		return compact(tx, retention)
+	})
+	if err != nil {
+		return fmt.Errorf("не удалось записать агрегаты в хранилище %s: %v", path, err)
+	}
+
+	return nil
+}
+
+func (store *RollupStore) Sources(path string) ([]domain.FileCheckpoint, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return []domain.FileCheckpoint{}, nil
+	}
+
+	db, err := store.open(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	defer db.Close()
+
+	files := make([]domain.FileCheckpoint, 0)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sourceBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(_, value []byte) error {
+			var file domain.FileCheckpoint
+
+			if err := json.Unmarshal(value, &file); err != nil {
+				return err
+			}
+
+			files = append(files, file)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать позиции файлов из хранилища %s: %v", path, err)
+	}
+
+	return files, nil
+}
+
+func (store *RollupStore) Query(path string, from, to time.Time) ([]domain.Rollup, error) {
+	db, err := store.open(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	defer db.Close()
+
+	rollups := make([]domain.Rollup, 0)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{minuteBucket, hourBucket} {
+			bucket := tx.Bucket(name)
+			if bucket == nil {
+				continue
+			}
+
+			cursor := bucket.Cursor()
+
+			key, value := cursor.First()
+			if !from.IsZero() {
+				key, value = cursor.Seek(encodeKey(bucketStart(name, from)))
+			}
+
+			for ; key != nil; key, value = cursor.Next() {
+				if !to.IsZero() && !decodeKey(key).Before(to) {
+					break
+				}
+
+				var rollup domain.Rollup
+
+				if err := json.Unmarshal(value, &rollup); err != nil {
+					return err
+				}
+
+				rollups = append(rollups, rollup)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать агрегаты из хранилища %s: %v", path, err)
+	}
+
+	sort.SliceStable(rollups, func(i, j int) bool {
+		return rollups[i].Start.Before(rollups[j].Start)
+	})
+
+	return rollups, nil
+}
+
+func (store *RollupStore) open(path string, readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: store.timeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть хранилище %s: %v", path, err)
+	}
+
+	return db, nil
+}
+
+func bucketStart(name []byte, moment time.Time) time.Time {
+	if string(name) == string(hourBucket) {
+		return moment.UTC().Truncate(time.Hour)
+	}
+
+	return moment.UTC().Truncate(time.Minute)
+}
+
+func putSources(tx *bolt.Tx, files []domain.FileCheckpoint) error {
+	bucket, err := tx.CreateBucketIfNotExists(sourceBucket)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		value, err := json.Marshal(file)
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte(file.Path), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func compact(tx *bolt.Tx, retention domain.RetentionOptions) error {
+	minutes, hours := tx.Bucket(minuteBucket), tx.Bucket(hourBucket)
+
+	latest, ok := latestStart(minutes, hours)
+	if !ok {
+		return nil
+	}
+
+	if retention.Downsample > 0 {
+		expired, err := collectBefore(minutes, latest.Add(-retention.Downsample))
+		if err != nil {
+			return err
+		}
+
+		for ind := range expired {
+			hourly := domain.NewRollup(expired[ind].Start.Truncate(time.Hour))
+			hourly.Merge(&expired[ind])
+
+			if err := putRollup(hours, &hourly); err != nil {
+				return err
+			}
+
+			if err := minutes.Delete(encodeKey(expired[ind].Start)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if retention.Keep > 0 {
+		cutoff := latest.Add(-retention.Keep)
+
+		for _, bucket := range []*bolt.Bucket{minutes, hours} {
+			expired, err := collectBefore(bucket, cutoff)
+			if err != nil {
+				return err
+			}
+
+			for ind := range expired {
+				if err := bucket.Delete(encodeKey(expired[ind].Start)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func putRollup(bucket *bolt.Bucket, rollup *domain.Rollup) error {
+	key := encodeKey(rollup.Start)
+	stored := domain.NewRollup(rollup.Start)
+
+	if value := bucket.Get(key); value != nil {
+		if err := json.Unmarshal(value, &stored); err != nil {
+			return err
+		}
+	}
+
+	stored.Merge(rollup)
+
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, value)
+}
+
+func collectBefore(bucket *bolt.Bucket, cutoff time.Time) ([]domain.Rollup, error) {
+	rollups := make([]domain.Rollup, 0)
+	cursor := bucket.Cursor()
+
+	for key, value := cursor.First(); key != nil && decodeKey(key).Before(cutoff); key, value = cursor.Next() {
+		var rollup domain.Rollup
+
+		if err := json.Unmarshal(value, &rollup); err != nil {
+			return nil, err
+		}
+
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, nil
+}
+
+func latestStart(buckets ...*bolt.Bucket) (time.Time, bool) {
+	var latest time.Time
+
+	found := false
+
+	for _, bucket := range buckets {
+		if key, _ := bucket.Cursor().Last(); key != nil && (!found || decodeKey(key).After(latest)) {
+			latest, found = decodeKey(key), true
+		}
+	}
+
+	return latest, found
+}
+
+func encodeKey(start time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(start.Unix()))
+
+	return key
+}
+
+func decodeKey(key []byte) time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint64(key)), 0).UTC()
+}
@@ -0,0 +1,106 @@
+package rollupstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRollup(start time.Time, url string, requests int) domain.Rollup {
+	rollup := domain.NewRollup(start)
+
+	for range requests {
+		rollup.Add(&domain.LogRecord{
+			RemoteAddr:    "10.0.0.1",
+			TimeLocal:     start.Add(10 * time.Second),
+			URL:           url,
+			Status:        200,
+			BodyBytesSent: 100,
+		})
+	}
+
+	return rollup
+}
+
+func TestRollupStore(t *testing.T) {
+	store := NewRollupStore()
+	start := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("IngestAndQuery", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.db")
+
+		require.NoError(t, store.Ingest(path, []domain.Rollup{
+			createRollup(start, "/", 2),
+			createRollup(start.Add(time.Minute), "/about", 1),
+		}, nil, domain.RetentionOptions{}))
+		require.NoError(t, store.Ingest(path, []domain.Rollup{createRollup(start, "/", 3)}, nil, domain.RetentionOptions{}))
+
+		rollups, err := store.Query(path, time.Time{}, time.Time{})
+
+		require.NoError(t, err)
+		require.Len(t, rollups, 2)
+		assert.Equal(t, 5, rollups[0].Requests)
+		assert.Equal(t, domain.Usage{Requests: 5, Bytes: 500}, rollups[0].URLs["/"])
+
+		rollups, err = store.Query(path, start.Add(time.Minute), start.Add(time.Hour))
+
+		require.NoError(t, err)
+		require.Len(t, rollups, 1)
+		assert.Equal(t, 1, rollups[0].URLs["/about"].Requests)
+	})
+
+	t.Run("Retention", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.db")
+		retention := domain.RetentionOptions{Keep: 48 * time.Hour, Downsample: 2 * time.Hour}
+
+		require.NoError(t, store.Ingest(path, []domain.Rollup{
+			createRollup(start.Add(-72*time.Hour), "/old", 1),
+			createRollup(start.Add(-3*time.Hour+time.Minute), "/", 2),
+			createRollup(start.Add(-3*time.Hour+2*time.Minute), "/", 3),
+			createRollup(start, "/", 1),
+		}, nil, retention))
+
+		rollups, err := store.Query(path, time.Time{}, time.Time{})
+
+		require.NoError(t, err)
+		require.Len(t, rollups, 2)
+		assert.Equal(t, start.Add(-3*time.Hour), rollups[0].Start)
+		assert.Equal(t, 5, rollups[0].Requests)
+		assert.Equal(t, start, rollups[1].Start)
+
+		rollups, err = store.Query(path, start.Add(-3*time.Hour+30*time.Minute), time.Time{})
+		require.NoError(t, err)
+		require.Len(t, rollups, 2, "Почасовой агрегат, пересекающий начало периода, должен попадать в отчёт")
+		assert.Equal(t, start.Add(-3*time.Hour), rollups[0].Start)
+	})
+
+	t.Run("Sources", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.db")
+
+		files, err := store.Sources(path)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+
+		first := domain.FileCheckpoint{Path: "/var/log/a.log", Inode: 1, Size: 100, Offset: 100}
+		second := domain.FileCheckpoint{Path: "/var/log/b.log", Inode: 2, Size: 50, Offset: 50}
+
+		require.NoError(t, store.Ingest(path, nil, []domain.FileCheckpoint{first, second}, domain.RetentionOptions{}))
+
+		first.Size, first.Offset = 300, 300
+		require.NoError(t, store.Ingest(path, nil, []domain.FileCheckpoint{first}, domain.RetentionOptions{}))
+
+		files, err = store.Sources(path)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []domain.FileCheckpoint{first, second}, files)
+	})
+
+	t.Run("MissingStore", func(t *testing.T) {
+		_, err := store.Query(filepath.Join(t.TempDir(), "missing.db"), time.Time{}, time.Time{})
+		assert.Error(t, err)
+	})
+}
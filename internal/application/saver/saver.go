@@ -8,6 +8,7 @@ import (
 const (
 	formatADOC     = "adoc"
 	formatMarkdown = "markdown"
+	formatJSON     = "json"
 )
 
 type Saver struct{}
@@ -24,6 +25,8 @@ func (saver *Saver) Save(output, name, format string) error {
 		filename = name + ".adoc"
 	case formatMarkdown:
 		filename = name + ".md"
+	case formatJSON:
+		filename = name + ".json"
 	default:
 		return fmt.Errorf("неподдеживаемый формат: %s", format)
 	}
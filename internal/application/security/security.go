@@ -0,0 +1,164 @@
+package security
+
+import (
+	domain "analyzer/internal/domain"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SignalUnusualMethod = "Необычный метод"
+	SignalAuthFailures  = "Много ошибок доступа"
+	SignalPostBurst     = "Всплеск POST-запросов"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+var (
+	defaultRuleSet     *Rules
+	defaultRuleSetOnce sync.Once
+)
+
+type ruleSet struct {
+	Patterns     []ruleSpec      `json:"patterns"`
+	Methods      []string        `json:"methods"`
+	AuthFailures authFailureSpec `json:"auth_failures"`
+	PostBurst    postBurstSpec   `json:"post_burst"`
+}
+
+type ruleSpec struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+type authFailureSpec struct {
+	Statuses    []int   `json:"statuses"`
+	MinRequests int     `json:"min_requests"`
+	Ratio       float64 `json:"ratio"`
+}
+
+type postBurstSpec struct {
+	Window      string `json:"window"`
+	MinRequests int    `json:"min_requests"`
+}
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+type Rules struct {
+	patterns     []rule
+	methods      map[string]bool
+	authStatuses map[int]bool
+
+	AuthMinRequests  int
+	AuthRatio        float64
+	BurstWindow      time.Duration
+	BurstMinRequests int
+}
+
+func NewRules(data []byte) (*Rules, error) {
+	var spec ruleSet
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать правила безопасности: %v", err)
+	}
+
+	rules := &Rules{
+		methods:          make(map[string]bool),
+		authStatuses:     make(map[int]bool),
+		AuthMinRequests:  spec.AuthFailures.MinRequests,
+		AuthRatio:        spec.AuthFailures.Ratio,
+		BurstMinRequests: spec.PostBurst.MinRequests,
+	}
+
+	for _, pattern := range spec.Patterns {
+		compiled, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("не удалась компиляция правила безопасности %s: %v", pattern.Name, err)
+		}
+
+		rules.patterns = append(rules.patterns, rule{name: pattern.Name, pattern: compiled})
+	}
+
+	for _, method := range spec.Methods {
+		rules.methods[strings.ToUpper(method)] = true
+	}
+
+	for _, status := range spec.AuthFailures.Statuses {
+		rules.authStatuses[status] = true
+	}
+
+	if spec.PostBurst.Window != "" {
+		window, err := time.ParseDuration(spec.PostBurst.Window)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("неверное окно всплеска POST-запросов в правилах безопасности: %s", spec.PostBurst.Window)
+		}
+
+		rules.BurstWindow = window
+	}
+
+	return rules, nil
+}
+
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл правил безопасности %s: %v", path, err)
+	}
+
+	return NewRules(data)
+}
+
+func DefaultRules() *Rules {
+	defaultRuleSetOnce.Do(func() {
+		rules, err := NewRules(defaultRules)
+		if err != nil {
+			panic(err)
+		}
+
+		defaultRuleSet = rules
+	})
+
+	return defaultRuleSet
+}
+
+func (rules *Rules) Match(record *domain.LogRecord) []string {
+	var signals []string
+
+	for _, rule := range rules.patterns {
+		if rule.pattern.MatchString(record.URL) {
+			signals = append(signals, rule.name)
+		}
+	}
+
+	if len(rules.methods) > 0 && !rules.methods[record.Method] {
+		signals = append(signals, SignalUnusualMethod)
+	}
+
+	return signals
+}
+
+func (rules *Rules) IsAuthFailure(status int) bool {
+	return rules.authStatuses[status]
+}
+
+func (rules *Rules) AuthFailureStatuses() []int {
+	statuses := make([]int, 0, len(rules.authStatuses))
+	for status := range rules.authStatuses {
+		statuses = append(statuses, status)
+	}
+
+	sort.Ints(statuses)
+
+	return statuses
+}
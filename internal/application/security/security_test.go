@@ -0,0 +1,78 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	rules := DefaultRules()
+
+	cases := map[string][]string{
+		"/static/../../etc/passwd":                     {"Обход каталогов"},
+		"/download?file=%2e%2e%2fconfig":               {"Обход каталогов"},
+		"/items?id=1+UNION+SELECT+password+FROM+users": {"SQL-инъекция"},
+		"/login?user=admin'%20OR%201=1":                {"SQL-инъекция"},
+		"/search?q=<script>alert(1)</script>":          {"XSS"},
+		"/ping?host=127.0.0.1;cat%20/etc/hosts":        {"Внедрение команд"},
+		"/.env":                                        {"Сканирование уязвимостей"},
+		"/wp-admin/install.php":                        {"Сканирование уязвимостей"},
+		"/api/users/42?sort=name":                      nil,
+		"/products/select-from-catalog":                nil,
+	}
+
+	for url, expected := range cases {
+		t.Run(url, func(t *testing.T) {
+			assert.Equal(t, expected, rules.Match(&domain.LogRecord{Method: "GET", URL: url}))
+		})
+	}
+
+	t.Run("UnusualMethod", func(t *testing.T) {
+		assert.Equal(t, []string{SignalUnusualMethod}, rules.Match(&domain.LogRecord{Method: "PROPFIND", URL: "/"}))
+		assert.Empty(t, rules.Match(&domain.LogRecord{Method: "DELETE", URL: "/"}))
+	})
+}
+
+func TestLoadRules(t *testing.T) {
+	t.Run("CustomRules", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		data := `{
+			"patterns": [{"name": "Админка", "pattern": "^/admin"}],
+			"auth_failures": {"statuses": [403, 401], "min_requests": 5, "ratio": 80},
+			"post_burst": {"window": "30s", "min_requests": 10}
+		}`
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+		rules, err := LoadRules(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Админка"}, rules.Match(&domain.LogRecord{Method: "TRACE", URL: "/admin/users"}))
+		assert.True(t, rules.IsAuthFailure(401))
+		assert.False(t, rules.IsAuthFailure(404))
+		assert.Equal(t, []int{401, 403}, rules.AuthFailureStatuses())
+		assert.Equal(t, 30*time.Second, rules.BurstWindow)
+		assert.Equal(t, 10, rules.BurstMinRequests)
+	})
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		_, err := NewRules([]byte(`{"patterns": [{"name": "broken", "pattern": "("}]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		_, err := NewRules([]byte(`{"post_burst": {"window": "soon", "min_requests": 3}}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := LoadRules(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	application "analyzer/internal/application"
+	metrics "analyzer/internal/application/metrics"
+	domain "analyzer/internal/domain"
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type MetricsServer struct {
+	app       *application.AnalyzerApp
+	config    *domain.Config
+	collector *metrics.Collector
+
+	refreshMutex sync.Mutex
+	files        []domain.FileCheckpoint
+
+	mutex   sync.Mutex
+	loaded  bool
+	loadErr error
+}
+
+func NewMetricsServer(app *application.AnalyzerApp, config *domain.Config) *MetricsServer {
+	config.URLOptions.CollapseIDs = true
+
+	return &MetricsServer{app: app, config: config, collector: metrics.NewCollector(config.LabelLimits)}
+}
+
+func (server *MetricsServer) Run() error {
+	watch := server.watch
+
+	if server.config.Path == "" {
+		watch = nil
+		server.loaded = true
+	}
+
+	defer server.app.Close()
+
+	return run(server.config, server.Handler(), watch, server.Receive)
+}
+
+func (server *MetricsServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", server.handleHealth)
+	mux.HandleFunc("GET /readyz", server.handleReady)
+	mux.HandleFunc("GET /metrics", server.handleMetrics)
+
+	return http.TimeoutHandler(mux, server.config.Serve.Timeout, timeoutMessage)
+}
+
+func (server *MetricsServer) watch(ctx context.Context) {
+	ticker := time.NewTicker(server.config.Serve.Refresh)
+	defer ticker.Stop()
+
+	for {
+		if err := server.Refresh(); err != nil {
+			log.Printf("Ошибка чтения логов: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (server *MetricsServer) Refresh() error {
+	server.refreshMutex.Lock()
+	defer server.refreshMutex.Unlock()
+
+	records, files, errs, err := server.app.RecordsIncremental(server.config, server.files)
+	if files != nil {
+		server.files = files
+	}
+
+	server.reject(errs)
+
+	if err == nil {
+		records, err = server.app.SelectRecords(records, server.config)
+	}
+
+	if err == nil {
+		server.collector.Observe(records)
+	} else {
+		server.collector.AddError()
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.loaded = server.loaded || err == nil
+	server.loadErr = err
+
+	return err
+}
+
+func (server *MetricsServer) Receive(lines []string, errs []error) {
+	records, lineErrs, err := server.app.ReceiveRecords(lines, server.config)
+	if err == nil {
+		records, err = server.app.SelectRecords(records, server.config)
+	}
+
+	server.reject(append(errs, lineErrs...))
+
+	if err != nil {
+		log.Printf("Ошибка обработки сообщений syslog: %v", err)
+		server.collector.AddError()
+
+		return
+	}
+
+	server.collector.Observe(records)
+}
+
+func (server *MetricsServer) reject(errs []error) {
+	application.LogRejected(errs)
+
+	for range errs {
+		server.collector.AddError()
+	}
+}
+
+func (server *MetricsServer) handleHealth(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (server *MetricsServer) handleReady(writer http.ResponseWriter, _ *http.Request) {
+	server.mutex.Lock()
+	loaded, err := server.loaded, server.loadErr
+	server.mutex.Unlock()
+
+	switch {
+	case loaded:
+		writeJSON(writer, http.StatusOK, map[string]string{"status": "ready"})
+	case err != nil:
+		writeJSON(writer, http.StatusServiceUnavailable, map[string]string{"status": "error", "error": err.Error()})
+	default:
+		writeJSON(writer, http.StatusServiceUnavailable, map[string]string{"status": "loading"})
+	}
+}
+
+func (server *MetricsServer) handleMetrics(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", metricsContentType)
+	writer.WriteHeader(http.StatusOK)
+
+	if _, err := server.collector.WriteTo(writer); err != nil {
+		log.Printf("Ошибка отправки метрик: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countRequests(t *testing.T, output string) int {
+	t.Helper()
+
+	total := 0
+
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "nginx_http_requests_total{") {
+			continue
+		}
+
+		count, err := strconv.Atoi(line[strings.LastIndex(line, " ")+1:])
+		require.NoError(t, err)
+
+		total += count
+	}
+
+	return total
+}
+
+func TestMetricsServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	logs := createLogs(60)
+	require.NoError(t, os.WriteFile(path, []byte(logs[:len(logs)/2]), 0o600))
+
+	server := NewMetricsServer(createApp(), createConfig(t, path))
+	handler := server.Handler()
+
+	metrics := func() string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, metricsContentType, recorder.Header().Get("Content-Type"))
+
+		return recorder.Body.String()
+	}
+
+	ready := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, ready())
+
+	require.NoError(t, server.Refresh())
+	assert.Equal(t, http.StatusOK, ready())
+	assert.Equal(t, 30, countRequests(t, metrics()))
+	assert.Contains(t, metrics(), `nginx_http_requests_total{method="GET",status="500",route="/page/{id}"} 8`)
+
+	require.NoError(t, os.WriteFile(path, []byte(logs), 0o600))
+	require.NoError(t, server.Refresh())
+	assert.Equal(t, 60, countRequests(t, metrics()))
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+
+	_, err = file.WriteString("garbage\n" + createLogs(10))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	require.NoError(t, server.Refresh())
+	assert.Equal(t, http.StatusOK, ready())
+	assert.Contains(t, metrics(), "nginx_log_exporter_errors_total 1\n")
+	assert.Equal(t, 70, countRequests(t, metrics()))
+
+	require.NoError(t, server.Refresh())
+	assert.Contains(t, metrics(), "nginx_log_exporter_errors_total 1\n")
+	assert.Equal(t, 70, countRequests(t, metrics()))
+}
+
+func TestMetricsServer_Syslog(t *testing.T) {
+	config := createConfig(t, "")
+	require.NoError(t, config.AddSyslog("udp://127.0.0.1:0"))
+
+	server := NewMetricsServer(createApp(), config)
+	logs := strings.Split(strings.TrimSpace(createLogs(20)), "\n")
+
+	server.Receive(logs, nil)
+	server.Receive([]string{"garbage"}, []error{fmt.Errorf("сообщение syslog без приоритета")})
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, 20, countRequests(t, recorder.Body.String()))
+	assert.Contains(t, recorder.Body.String(), "nginx_log_exporter_errors_total 2\n")
+}
@@ -0,0 +1,618 @@
+package server
+
+import (
+	application "analyzer/internal/application"
+	syslog "analyzer/internal/application/syslog"
+	domain "analyzer/internal/domain"
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	writeTimeoutSlack = 5 * time.Second
+	shutdownTimeout   = 10 * time.Second
+
+	timeoutMessage = `{"error": "превышено время обработки запроса"}`
+
+	dashboardTopLimit = 10
+)
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+var contentTypes = map[string]string{
+	domain.MarkdownFormat: "text/markdown; charset=utf-8",
+	domain.AdocFormat:     "text/asciidoc; charset=utf-8",
+	domain.JSONFormat:     "application/json; charset=utf-8",
+}
+
+type dashboardReport struct {
+	TotalRequests    int
+	DroppedRecords   int
+	ClientErrors     int
+	ServerErrors     int
+	TotalBytes       int64
+	AvgBodySize      int
+	Percentile95Size int
+	Timeline         []domain.TimeBucket
+	TopResources     []domain.ValueCount
+	TopIPAddresses   []domain.IPCount
+	ResponseCodes    []dashboardCode
+}
+
+type dashboardCode struct {
+	Code  int
+	Name  string
+	Count int
+}
+
+type Server struct {
+	app       *application.AnalyzerApp
+	config    *domain.Config
+	dashboard bool
+
+	refreshMutex sync.Mutex
+	mutex        sync.RWMutex
+	records      []domain.LogRecord
+	files        []domain.FileCheckpoint
+	version      int
+	dropped      int
+	rejected     int
+	loadErr      error
+}
+
+func NewServer(app *application.AnalyzerApp, config *domain.Config) *Server {
+	return &Server{app: app, config: config}
+}
+
+func NewDashboard(app *application.AnalyzerApp, config *domain.Config) *Server {
+	return &Server{app: app, config: config, dashboard: true}
+}
+
+func (server *Server) Run() error {
+	watch := server.watch
+	if server.config.Path == "" {
+		watch = nil
+	}
+
+	if len(server.config.Syslog) > 0 {
+		server.version = 1
+	}
+
+	defer server.app.Close()
+
+	return run(server.config, server.Handler(), watch, server.Receive)
+}
+
+func run(config *domain.Config, handler http.Handler, watch func(ctx context.Context),
+	receive func(lines []string, errs []error)) error {
+	var receiver *syslog.Receiver
+
+	if len(config.Syslog) > 0 {
+		var err error
+
+		receiver, err = syslog.Listen(config.Syslog, config.Serve.Refresh)
+		if err != nil {
+			return err
+		}
+
+		defer receiver.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              config.Serve.Listen,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       config.Serve.Timeout,
+		WriteTimeout:      config.Serve.Timeout + writeTimeoutSlack,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	if watch != nil {
+		go watch(ctx)
+	}
+
+	if receiver != nil {
+		go receiver.Serve(ctx, receive)
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return httpServer.Shutdown(ctx)
+}
+
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", server.handleHealth)
+	mux.HandleFunc("GET /readyz", server.handleReady)
+	mux.HandleFunc("GET /report", server.handleReport)
+	mux.HandleFunc("POST /analyze", server.handleAnalyze)
+
+	if !server.dashboard {
+		return http.TimeoutHandler(mux, server.config.Serve.Timeout, timeoutMessage)
+	}
+
+	static, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		log.Fatalf("Ошибка: %v", err)
+	}
+
+	mux.Handle("GET /", http.FileServerFS(static))
+
+	root := http.NewServeMux()
+
+	root.HandleFunc("GET /events", server.handleEvents)
+	root.Handle("/", http.TimeoutHandler(mux, server.config.Serve.Timeout, timeoutMessage))
+
+	return root
+}
+
+func (server *Server) watch(ctx context.Context) {
+	if err := server.reload(); err != nil {
+		log.Printf("Ошибка загрузки логов: %v", err)
+	}
+
+	if !server.dashboard {
+		return
+	}
+
+	ticker := time.NewTicker(server.config.Serve.Refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := server.Refresh(); err != nil {
+				log.Printf("Ошибка обновления логов: %v", err)
+			}
+		}
+	}
+}
+
+func (server *Server) reload() error {
+	if server.dashboard {
+		return server.Refresh()
+	}
+
+	return server.Load()
+}
+
+func (server *Server) Load() error {
+	records, err := server.app.Records(server.config)
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if err != nil {
+		server.loadErr = err
+		return err
+	}
+
+	server.records = records
+	server.dropped = 0
+	server.version++
+	server.loadErr = nil
+
+	return nil
+}
+
+func (server *Server) Refresh() error {
+	if server.config.TypePath != "local" {
+		return server.Load()
+	}
+
+	server.refreshMutex.Lock()
+	defer server.refreshMutex.Unlock()
+
+	records, files, errs, err := server.app.RecordsIncremental(server.config, server.files)
+	server.reject(errs)
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if files != nil {
+		server.files = files
+	}
+
+	if err != nil {
+		server.loadErr = err
+		return err
+	}
+
+	server.loadErr = nil
+
+	if server.version > 0 && len(records) == 0 {
+		return nil
+	}
+
+	server.appendRecords(records)
+
+	return nil
+}
+
+func (server *Server) Receive(lines []string, errs []error) {
+	records, lineErrs, err := server.app.ReceiveRecords(lines, server.config)
+	server.reject(append(errs, lineErrs...))
+
+	if err != nil {
+		log.Printf("Ошибка обработки сообщений syslog: %v", err)
+		return
+	}
+
+	if len(records) == 0 {
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.appendRecords(records)
+}
+
+func (server *Server) appendRecords(records []domain.LogRecord) {
+	records = append(server.records, records...)
+	server.records = latestRecords(records, server.config.Serve.MaxRecords)
+	server.dropped += len(records) - len(server.records)
+	server.version++
+}
+
+func (server *Server) reject(errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+
+	server.mutex.Lock()
+	server.rejected += len(errs)
+	server.mutex.Unlock()
+
+	application.LogRejected(errs)
+}
+
+func latestRecords(records []domain.LogRecord, limit int) []domain.LogRecord {
+	if limit > 0 && len(records) > limit {
+		return records[len(records)-limit:]
+	}
+
+	return records
+}
+
+func (server *Server) snapshot() (records []domain.LogRecord, dropped, version int, err error) {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+
+	return server.records, server.dropped, server.version, server.loadErr
+}
+
+func (server *Server) handleHealth(writer http.ResponseWriter, _ *http.Request) {
+	server.mutex.RLock()
+	rejected := server.rejected
+	server.mutex.RUnlock()
+
+	writeJSON(writer, http.StatusOK, map[string]any{"status": "ok", "rejected": rejected})
+}
+
+func (server *Server) handleReady(writer http.ResponseWriter, _ *http.Request) {
+	if server.config.Path == "" {
+		writeJSON(writer, http.StatusOK, map[string]string{"status": "ready"})
+		return
+	}
+
+	_, _, version, err := server.snapshot()
+
+	switch {
+	case version > 0:
+		writeJSON(writer, http.StatusOK, map[string]string{"status": "ready"})
+	case err != nil:
+		writeJSON(writer, http.StatusServiceUnavailable, map[string]string{"status": "error", "error": err.Error()})
+	default:
+		writeJSON(writer, http.StatusServiceUnavailable, map[string]string{"status": "loading"})
+	}
+}
+
+func (server *Server) handleReport(writer http.ResponseWriter, request *http.Request) {
+	config, err := server.requestConfig(request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	if server.config.Path == "" && len(server.config.Syslog) == 0 {
+		writeError(writer, http.StatusNotFound, fmt.Errorf("путь к логам не задан, загрузите лог через POST /analyze"))
+		return
+	}
+
+	records, dropped, version, err := server.snapshot()
+	if version == 0 {
+		writeError(writer, http.StatusServiceUnavailable, loadingError(err))
+		return
+	}
+
+	server.writeReport(writer, records, dropped, &config)
+}
+
+func (server *Server) handleEvents(writer http.ResponseWriter, request *http.Request) {
+	config, err := server.requestConfig(request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	controller := http.NewResponseController(writer)
+
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(server.config.Serve.Refresh)
+	defer ticker.Stop()
+
+	sent := -1
+
+	for {
+		records, dropped, version, err := server.snapshot()
+
+		if version != sent {
+			sent = version
+
+			if err := server.writeReportEvent(writer, records, dropped, version, err, &config); err != nil {
+				return
+			}
+
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-request.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (server *Server) handleAnalyze(writer http.ResponseWriter, request *http.Request) {
+	config, err := server.requestConfig(request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, server.config.Serve.MaxUpload))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			writeError(writer, http.StatusRequestEntityTooLarge,
+				fmt.Errorf("размер лога превышает допустимые %d МиБ", server.config.Serve.MaxUpload>>20))
+
+			return
+		}
+
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("не удалось прочитать тело запроса: %v", err))
+
+		return
+	}
+
+	if len(body) > 0 {
+		config.Path = ""
+		config.TypePath = "upload"
+
+		records, err := server.app.ReadRecords(bytes.NewReader(body), &config)
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, err)
+			return
+		}
+
+		server.writeReport(writer, records, 0, &config)
+
+		return
+	}
+
+	switch {
+	case server.config.Path != "":
+		if err := server.reload(); err != nil {
+			writeError(writer, http.StatusInternalServerError, err)
+			return
+		}
+	case len(server.config.Syslog) == 0:
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("передайте лог в теле запроса или запустите сервер с --path"))
+		return
+	}
+
+	records, dropped, _, _ := server.snapshot()
+
+	server.writeReport(writer, records, dropped, &config)
+}
+
+func (server *Server) requestConfig(request *http.Request) (domain.Config, error) {
+	config := *server.config
+	query := request.URL.Query()
+
+	if query.Has("format") {
+		if err := config.AddFormat(query.Get("format")); err != nil {
+			return config, err
+		}
+	}
+
+	if query.Has("from") {
+		if err := config.AddFrom(query.Get("from")); err != nil {
+			return config, err
+		}
+	}
+
+	if query.Has("to") {
+		if err := config.AddTo(query.Get("to")); err != nil {
+			return config, err
+		}
+	}
+
+	if query.Has("bucket") {
+		if err := config.AddBucketSize(query.Get("bucket")); err != nil {
+			return config, err
+		}
+	}
+
+	if where := query.Get("where"); where != "" {
+		field, value, found := strings.Cut(where, "=")
+		if !found {
+			return config, fmt.Errorf("неверный фильтр where, ожидается поле=выражение: %s", where)
+		}
+
+		if err := config.AddFilterField(field); err != nil {
+			return config, err
+		}
+
+		if err := config.AddFilterValue(value); err != nil {
+			return config, err
+		}
+	}
+
+	return config, nil
+}
+
+func (server *Server) writeReport(writer http.ResponseWriter, records []domain.LogRecord, dropped int, config *domain.Config) {
+	report, err := server.analyze(records, dropped, config)
+	if err != nil {
+		writeError(writer, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	output, err := server.app.Formatter.Format(&report, config.Format)
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", contentTypes[config.Format])
+	writer.WriteHeader(http.StatusOK)
+
+	if _, err := io.WriteString(writer, output); err != nil {
+		log.Printf("Ошибка отправки отчёта: %v", err)
+	}
+}
+
+func (server *Server) writeReportEvent(writer io.Writer, records []domain.LogRecord, dropped, version int, loadErr error,
+	config *domain.Config) error {
+	if version == 0 {
+		return writeEvent(writer, "failure", map[string]string{"error": loadingError(loadErr).Error()})
+	}
+
+	report, err := server.analyze(records, dropped, config)
+	if err != nil {
+		return writeEvent(writer, "failure", map[string]string{"error": err.Error()})
+	}
+
+	return writeEvent(writer, "report", newDashboardReport(&report))
+}
+
+func (server *Server) analyze(records []domain.LogRecord, dropped int, config *domain.Config) (domain.LogReport, error) {
+	report, err := server.app.AnalyzeRecords(records, config)
+	if err != nil {
+		return domain.LogReport{}, err
+	}
+
+	report.DroppedRecords = dropped
+
+	return report, nil
+}
+
+func newDashboardReport(report *domain.LogReport) dashboardReport {
+	resources := make([]domain.ValueCount, 0, dashboardTopLimit)
+	for _, url := range report.SortedRequestedResources[:min(dashboardTopLimit, len(report.SortedRequestedResources))] {
+		resources = append(resources, domain.ValueCount{Value: url, Count: report.RequestedResources[url]})
+	}
+
+	codes := make([]dashboardCode, 0, len(report.SortedResponseCodes))
+	for _, code := range report.SortedResponseCodes {
+		status := report.ResponseCodes[code]
+		codes = append(codes, dashboardCode{Code: code, Name: status.Name, Count: status.Count})
+	}
+
+	return dashboardReport{
+		TotalRequests:    report.TotalRequests,
+		DroppedRecords:   report.DroppedRecords,
+		ClientErrors:     report.ClientErrors,
+		ServerErrors:     report.ServerErrors,
+		TotalBytes:       report.Traffic.TotalBytes,
+		AvgBodySize:      report.AvgBodySize,
+		Percentile95Size: report.Percentile95Size,
+		Timeline:         report.Timeline,
+		TopResources:     resources,
+		TopIPAddresses:   report.TopIPAddresses,
+		ResponseCodes:    codes,
+	}
+}
+
+func loadingError(err error) error {
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("логи ещё загружаются")
+}
+
+func writeEvent(writer io.Writer, event string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event, data)
+
+	return err
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", contentTypes[domain.JSONFormat])
+	writer.WriteHeader(status)
+
+	if err := json.NewEncoder(writer).Encode(value); err != nil {
+		log.Printf("Ошибка отправки ответа: %v", err)
+	}
+}
+
+func writeError(writer http.ResponseWriter, status int, err error) {
+	writeJSON(writer, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	application "analyzer/internal/application"
+	analyzer "analyzer/internal/application/analyzer"
+	anonymizer "analyzer/internal/application/anonymizer"
+	enricher "analyzer/internal/application/enricher"
+	filter "analyzer/internal/application/filter"
+	formatter "analyzer/internal/application/formatter"
+	normalizer "analyzer/internal/application/normalizer"
+	parsers "analyzer/internal/application/parsers"
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createLogs(count int) string {
+	lines := make([]string, 0, count)
+
+	for i := range count {
+		status := 200
+		if i%4 == 0 {
+			status = 500
+		}
+
+		lines = append(lines, fmt.Sprintf(`10.0.0.%d - - [12/Oct/2023:14:%02d:00 +0000] "GET /page/%d HTTP/1.1" %d 100 "-" "Mozilla/5.0"`,
+			i%10, i%60, i%3, status))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func createApp() *application.AnalyzerApp {
+	return &application.AnalyzerApp{
+		LogParser:     parsers.NewLogParser(),
+		URLNormalizer: normalizer.NewURLNormalizer(),
+		LogEnricher:   enricher.NewLogEnricher(),
+		LogAnalyzer:   analyzer.NewLogAnalyzer(),
+		LogFilter:     filter.NewLogFilter(),
+		LogAnonymizer: anonymizer.NewLogAnonymizer(),
+		Formatter:     formatter.NewFormatter(),
+	}
+}
+
+func createConfig(t *testing.T, path string) *domain.Config {
+	t.Helper()
+
+	config := &domain.Config{
+		Format: domain.JSONFormat,
+		Serve:  domain.ServeOptions{Timeout: time.Minute, MaxUpload: 1 << 20, Refresh: 10 * time.Millisecond},
+	}
+
+	require.NoError(t, config.AddServeSource(path))
+	require.NoError(t, config.AddFilterValue(""))
+	require.NoError(t, config.AddLabelLimits(""))
+
+	return config
+}
+
+func createServer(t *testing.T, path string) *Server {
+	t.Helper()
+
+	return NewServer(createApp(), createConfig(t, path))
+}
+
+func serve(server *Server, method, target, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	return recorder
+}
+
+func decodeReport(t *testing.T, recorder *httptest.ResponseRecorder) domain.LogReport {
+	t.Helper()
+
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var report domain.LogReport
+
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+
+	return report
+}
+
+func TestServer_Report(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	require.NoError(t, os.WriteFile(path, []byte(createLogs(40)), 0o600))
+
+	server := createServer(t, path)
+	server.config.Serve.MaxRecords = 10
+
+	assert.Equal(t, http.StatusOK, serve(server, http.MethodGet, "/healthz", "").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(server, http.MethodGet, "/readyz", "").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(server, http.MethodGet, "/report", "").Code)
+
+	require.NoError(t, server.Load())
+
+	assert.Equal(t, http.StatusOK, serve(server, http.MethodGet, "/readyz", "").Code)
+	assert.Equal(t, 40, decodeReport(t, serve(server, http.MethodGet, "/report", "")).TotalRequests,
+		"Ожидалось, что --max-records не ограничивает разовую загрузку --path")
+	assert.Equal(t, 10, decodeReport(t, serve(server, http.MethodGet, "/report?where=status=^5", "")).TotalRequests)
+
+	recorder := serve(server, http.MethodGet, "/report?format=markdown", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	assert.Equal(t, http.StatusBadRequest, serve(server, http.MethodGet, "/report?where=status", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(server, http.MethodGet, "/report?from=yesterday", "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(server, http.MethodGet, "/report?from=2024-01-01", "").Code)
+
+	t.Run("Reload", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(createLogs(60)), 0o600))
+
+		assert.Equal(t, 60, decodeReport(t, serve(server, http.MethodPost, "/analyze", "")).TotalRequests)
+		assert.Equal(t, 60, decodeReport(t, serve(server, http.MethodGet, "/report", "")).TotalRequests)
+	})
+}
+
+func TestServer_Analyze(t *testing.T) {
+	server := createServer(t, "")
+
+	assert.Equal(t, http.StatusOK, serve(server, http.MethodGet, "/readyz", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(server, http.MethodGet, "/report", "").Code)
+
+	report := decodeReport(t, serve(server, http.MethodPost, "/analyze?where=url=/page/1", createLogs(30)))
+	assert.Equal(t, 10, report.TotalRequests)
+	assert.Empty(t, report.FileNames)
+
+	assert.Equal(t, http.StatusBadRequest, serve(server, http.MethodPost, "/analyze", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(server, http.MethodPost, "/analyze", "garbage\n").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(server, http.MethodGet, "/analyze", "").Code)
+
+	server.config.Serve.MaxUpload = 100
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(server, http.MethodPost, "/analyze", createLogs(30)).Code)
+}
+
+func TestServer_Syslog(t *testing.T) {
+	config := createConfig(t, "")
+	require.NoError(t, config.AddSyslog("udp://127.0.0.1:0"))
+
+	server := NewServer(createApp(), config)
+	logs := strings.Split(strings.TrimSpace(createLogs(20)), "\n")
+
+	server.Receive(logs[:10], nil)
+	server.Receive(append(logs[10:], "garbage"), []error{fmt.Errorf("сообщение syslog без приоритета")})
+
+	assert.Equal(t, 20, decodeReport(t, serve(server, http.MethodGet, "/report", "")).TotalRequests)
+	assert.Equal(t, 5, decodeReport(t, serve(server, http.MethodGet, "/report?where=status=^5", "")).TotalRequests)
+	assert.Equal(t, 20, decodeReport(t, serve(server, http.MethodPost, "/analyze", "")).TotalRequests)
+	assert.Contains(t, serve(server, http.MethodGet, "/healthz", "").Body.String(), `"rejected":2`)
+
+	server.config.Serve.MaxRecords = 15
+	server.Receive(logs[:10], nil)
+
+	report := decodeReport(t, serve(server, http.MethodGet, "/report", ""))
+	assert.Equal(t, 15, report.TotalRequests)
+	assert.Equal(t, 15, report.DroppedRecords)
+	assert.Contains(t, serve(server, http.MethodGet, "/report?format=markdown", "").Body.String(),
+		"| Старых записей отброшено (--max-records) | 15 |")
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) (event, data string) {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && event != "":
+			return event, data
+		}
+	}
+}
+
+func TestServer_Dashboard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	logs := createLogs(40)
+	require.NoError(t, os.WriteFile(path, []byte(logs[:strings.Index(logs, "\n")+1]), 0o600))
+
+	server := createServer(t, path)
+	server.dashboard = true
+
+	recorder := serve(server, http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "EventSource")
+
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	response, err := http.Get(httpServer.URL + "/events?where=status=^2")
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+
+	event, _ := readEvent(t, reader)
+	assert.Equal(t, "failure", event)
+
+	require.NoError(t, server.Refresh())
+
+	event, _ = readEvent(t, reader)
+	assert.Equal(t, "failure", event, "Ожидалось, что первая запись с кодом 500 не пройдёт фильтр")
+
+	require.NoError(t, os.WriteFile(path, []byte(logs), 0o600))
+	require.NoError(t, server.Refresh())
+
+	event, data := readEvent(t, reader)
+	require.Equal(t, "report", event)
+
+	var report dashboardReport
+
+	require.NoError(t, json.Unmarshal([]byte(data), &report))
+	assert.Equal(t, 30, report.TotalRequests)
+	assert.Len(t, report.TopResources, 3)
+	assert.Equal(t, []dashboardCode{{Code: 200, Name: "OK", Count: 30}}, report.ResponseCodes)
+	assert.NotContains(t, data, "RequestedResources")
+
+	require.NoError(t, server.Refresh())
+	assert.Equal(t, http.StatusOK, serve(server, http.MethodGet, "/readyz", "").Code)
+	assert.Equal(t, 40, decodeReport(t, serve(server, http.MethodGet, "/report", "")).TotalRequests)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+
+	_, err = file.WriteString("garbage\n" + createLogs(10))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	server.config.Serve.MaxRecords = 45
+	require.NoError(t, server.Refresh())
+
+	records, dropped, _, _ := server.snapshot()
+	assert.Len(t, records, 45)
+	assert.Equal(t, 5, dropped)
+	assert.Equal(t, "/page/0", records[len(records)-10].URL, "Ожидалось, что сохранятся последние записи")
+}
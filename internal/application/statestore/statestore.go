@@ -0,0 +1,100 @@
+package statestore
+
+import (
+	domain "analyzer/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type StateStore struct{}
+
+func NewStateStore() *StateStore {
+	return &StateStore{}
+}
+
+func (store *StateStore) Load(path string) (domain.AnalyzerState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.AnalyzerState{}, fmt.Errorf("невозможно прочитать состояние %s: %v", path, err)
+	}
+
+	var state domain.AnalyzerState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return domain.AnalyzerState{}, fmt.Errorf("неверный формат состояния %s: %v", path, err)
+	}
+
+	if state.Version != domain.StateVersion {
+		return domain.AnalyzerState{}, fmt.Errorf("неподдерживаемая версия состояния %s: %d", path, state.Version)
+	}
+
+	return state, nil
+}
+
+func (store *StateStore) Save(state *domain.AnalyzerState, path string) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("невозможно сохранить состояние: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("невозможно записать состояние в файл %s: %v", path, err)
+	}
+
+	return nil
+}
+
+func (store *StateStore) LoadCheckpoint(path string) (domain.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return domain.Checkpoint{Version: domain.CheckpointVersion}, nil
+	}
+
+	if err != nil {
+		return domain.Checkpoint{}, fmt.Errorf("невозможно прочитать контрольную точку %s: %v", path, err)
+	}
+
+	var checkpoint domain.Checkpoint
+
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return domain.Checkpoint{}, fmt.Errorf("неверный формат контрольной точки %s: %v", path, err)
+	}
+
+	if checkpoint.Version != domain.CheckpointVersion {
+		return domain.Checkpoint{}, fmt.Errorf("неподдерживаемая версия контрольной точки %s: %d", path, checkpoint.Version)
+	}
+
+	return checkpoint, nil
+}
+
+func (store *StateStore) SaveCheckpoint(checkpoint *domain.Checkpoint, path string) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("невозможно сохранить контрольную точку: %v", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("невозможно записать контрольную точку %s: %v", path, err)
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("невозможно записать контрольную точку %s: %v", path, err)
+	}
+
+	return nil
+}
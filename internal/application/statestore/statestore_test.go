@@ -0,0 +1,88 @@
+package statestore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateStore(t *testing.T) {
+	store := NewStateStore()
+	path := filepath.Join(t.TempDir(), "host1.state")
+
+	state := domain.AnalyzerState{
+		Version:       domain.StateVersion,
+		Settings:      domain.StateSettings{BucketSize: time.Hour},
+		FileNames:     []string{"access.log"},
+		TotalRequests: 3,
+		Accumulators:  []json.RawMessage{json.RawMessage(`[3,{"200":3}]`)},
+	}
+
+	t.Run("SaveAndLoad", func(t *testing.T) {
+		require.NoError(t, store.Save(&state, path))
+
+		loaded, err := store.Load(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, state, loaded)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"Version": 99}`), 0o600))
+
+		_, err := store.Load(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidState", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+		_, err := store.Load(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestStateStore_Checkpoint(t *testing.T) {
+	store := NewStateStore()
+	path := filepath.Join(t.TempDir(), "analyzer.checkpoint")
+
+	t.Run("MissingCheckpoint", func(t *testing.T) {
+		checkpoint, err := store.LoadCheckpoint(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.Checkpoint{Version: domain.CheckpointVersion}, checkpoint)
+	})
+
+	t.Run("SaveAndLoad", func(t *testing.T) {
+		checkpoint := domain.Checkpoint{
+			Version: domain.CheckpointVersion,
+			Files:   []domain.FileCheckpoint{{Path: "/var/log/nginx/access.log", Inode: 42, Size: 2048, Offset: 1990}},
+			State:   &domain.AnalyzerState{Version: domain.StateVersion, TotalRequests: 10},
+		}
+
+		require.NoError(t, store.SaveCheckpoint(&checkpoint, path))
+
+		loaded, err := store.LoadCheckpoint(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, checkpoint, loaded)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"Version": 99}`), 0o600))
+
+		_, err := store.LoadCheckpoint(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,128 @@
+package syslog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxPriority = 191
+	byteOrder   = "\ufeff"
+)
+
+func ParseMessage(message string) (string, error) {
+	message = strings.TrimRight(message, "\r\n\x00")
+
+	if !strings.HasPrefix(message, "<") {
+		return "", fmt.Errorf("сообщение syslog без приоритета: %s", message)
+	}
+
+	end := strings.IndexByte(message, '>')
+	if end < 2 || end > 4 {
+		return "", fmt.Errorf("неверный приоритет в сообщении syslog: %s", message)
+	}
+
+	priority, err := strconv.Atoi(message[1:end])
+	if err != nil || priority < 0 || priority > maxPriority {
+		return "", fmt.Errorf("неверный приоритет в сообщении syslog: %s", message)
+	}
+
+	body := message[end+1:]
+
+	if version, header, found := strings.Cut(body, " "); found && isVersion(version) {
+		return parseRFC5424(header)
+	}
+
+	return parseRFC3164(body), nil
+}
+
+func isVersion(value string) bool {
+	if value == "" || len(value) > 3 || value[0] == '0' {
+		return false
+	}
+
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseRFC5424(header string) (string, error) {
+	fields := strings.SplitN(header, " ", 6)
+	if len(fields) < 6 {
+		return "", fmt.Errorf("неполный заголовок RFC 5424 в сообщении syslog: %s", header)
+	}
+
+	message, err := skipStructuredData(fields[5])
+	if err != nil {
+		return "", err
+	}
+
+	if message != "" && message[0] != ' ' {
+		return "", fmt.Errorf("неверные структурированные данные в сообщении syslog: %s", fields[5])
+	}
+
+	message = strings.TrimPrefix(message, " ")
+
+	return strings.TrimPrefix(message, byteOrder), nil
+}
+
+func skipStructuredData(data string) (string, error) {
+	if strings.HasPrefix(data, "-") {
+		return data[1:], nil
+	}
+
+	if !strings.HasPrefix(data, "[") {
+		return "", fmt.Errorf("неверные структурированные данные в сообщении syslog: %s", data)
+	}
+
+	quoted := false
+
+	for ind := 0; ind < len(data); ind++ {
+		switch {
+		case data[ind] == '\\':
+			ind++
+		case data[ind] == '"':
+			quoted = !quoted
+		case data[ind] == ']' && !quoted:
+			if ind+1 == len(data) || data[ind+1] != '[' {
+				return data[ind+1:], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("незакрытые структурированные данные в сообщении syslog: %s", data)
+}
+
+func parseRFC3164(body string) string {
+	if len(body) < len(time.Stamp) {
+		return body
+	}
+
+	if _, err := time.Parse(time.Stamp, body[:len(time.Stamp)]); err != nil {
+		return body
+	}
+
+	header := strings.TrimPrefix(body[len(time.Stamp):], " ")
+
+	first, message, _ := strings.Cut(header, " ")
+	if isTag(first) {
+		return message
+	}
+
+	tag, rest, found := strings.Cut(message, " ")
+	if found && isTag(tag) {
+		return rest
+	}
+
+	return message
+}
+
+func isTag(value string) bool {
+	return len(value) > 1 && strings.HasSuffix(value, ":")
+}
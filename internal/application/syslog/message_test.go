@@ -0,0 +1,53 @@
+package syslog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const payload = `10.0.0.1 - - [12/Oct/2023:14:32:00 +0000] "GET /index.html HTTP/1.1" 200 1024 "-" "Mozilla/5.0"`
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{name: "RFC3164", message: "<190>Oct 12 14:32:00 web1 nginx: " + payload},
+		{name: "RFC3164Padded", message: "<190>Oct  2 14:32:00 web1 nginx[42]: " + payload + "\n"},
+		{name: "RFC3164WithoutHostname", message: "<190>Oct 12 14:32:00 nginx: " + payload},
+		{name: "RFC3164WithoutTimestamp", message: "<190>" + payload},
+		{name: "RFC5424", message: "<190>1 2023-10-12T14:32:00.000Z web1 nginx 42 access - " + payload},
+		{name: "RFC5424WithBOM", message: "<190>1 2023-10-12T14:32:00Z web1 nginx - - - \ufeff" + payload + "\r\n"},
+		{
+			name:    "RFC5424WithStructuredData",
+			message: `<190>1 2023-10-12T14:32:00Z web1 nginx - - [meta region="eu\]" env="prod"][origin ip="10.0.0.2"] ` + payload,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line, err := ParseMessage(test.message)
+
+			require.NoError(t, err)
+			assert.Equal(t, payload, line)
+		})
+	}
+}
+
+func TestParseMessage_Invalid(t *testing.T) {
+	messages := []string{
+		payload,
+		"<abc>Oct 12 14:32:00 web1 nginx: " + payload,
+		"<192>Oct 12 14:32:00 web1 nginx: " + payload,
+		"<190>1 2023-10-12T14:32:00Z web1 nginx",
+		`<190>1 2023-10-12T14:32:00Z web1 nginx - - [meta region="eu"`,
+		"<190>1 2023-10-12T14:32:00Z web1 nginx - - unknown " + payload,
+	}
+
+	for _, message := range messages {
+		_, err := ParseMessage(message)
+		assert.Error(t, err, "Ожидалось, что выкинется ошибка для сообщения %q", message)
+	}
+}
@@ -0,0 +1,251 @@
+package syslog
+
+import (
+	domain "analyzer/internal/domain"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	maxMessageSize = 64 << 10
+	maxFrameDigits = 6
+	maxBatchSize   = 10_000
+	queueSize      = 1024
+	acceptDelay    = 100 * time.Millisecond
+)
+
+type message struct {
+	line string
+	err  error
+}
+
+type Receiver struct {
+	packets   []net.PacketConn
+	listeners []net.Listener
+	addrs     []net.Addr
+	flush     time.Duration
+	messages  chan message
+}
+
+func Listen(listeners []domain.SyslogListener, flush time.Duration) (*Receiver, error) {
+	receiver := &Receiver{flush: flush, messages: make(chan message, queueSize)}
+
+	for _, listener := range listeners {
+		switch listener.Network {
+		case domain.SyslogUDP:
+			conn, err := net.ListenPacket("udp", listener.Address)
+			if err != nil {
+				receiver.Close()
+				return nil, fmt.Errorf("не удалось открыть порт syslog udp://%s: %v", listener.Address, err)
+			}
+
+			receiver.packets = append(receiver.packets, conn)
+			receiver.addrs = append(receiver.addrs, conn.LocalAddr())
+		case domain.SyslogTCP:
+			stream, err := net.Listen("tcp", listener.Address)
+			if err != nil {
+				receiver.Close()
+				return nil, fmt.Errorf("не удалось открыть порт syslog tcp://%s: %v", listener.Address, err)
+			}
+
+			receiver.listeners = append(receiver.listeners, stream)
+			receiver.addrs = append(receiver.addrs, stream.Addr())
+		default:
+			receiver.Close()
+			return nil, fmt.Errorf("неподдерживаемый протокол syslog: %s", listener.Network)
+		}
+	}
+
+	return receiver, nil
+}
+
+func (receiver *Receiver) Addrs() []net.Addr {
+	return receiver.addrs
+}
+
+func (receiver *Receiver) Close() {
+	for _, conn := range receiver.packets {
+		conn.Close()
+	}
+
+	for _, listener := range receiver.listeners {
+		listener.Close()
+	}
+}
+
+func (receiver *Receiver) Serve(ctx context.Context, handle func(lines []string, errs []error)) {
+	stop := context.AfterFunc(ctx, receiver.Close)
+	defer stop()
+
+	for _, conn := range receiver.packets {
+		go receiver.readPackets(ctx, conn)
+	}
+
+	for _, listener := range receiver.listeners {
+		go receiver.accept(ctx, listener)
+	}
+
+	receiver.collect(ctx, handle)
+}
+
+func (receiver *Receiver) collect(ctx context.Context, handle func(lines []string, errs []error)) {
+	ticker := time.NewTicker(receiver.flush)
+	defer ticker.Stop()
+
+	lines := make([]string, 0)
+	errs := make([]error, 0)
+
+	flush := func() {
+		if len(lines) > 0 || len(errs) > 0 {
+			handle(lines, errs)
+			lines, errs = make([]string, 0), make([]error, 0)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flush()
+			return
+		case <-ticker.C:
+			flush()
+		case current := <-receiver.messages:
+			if current.err != nil {
+				errs = append(errs, current.err)
+			} else {
+				lines = append(lines, current.line)
+			}
+
+			if len(lines)+len(errs) >= maxBatchSize {
+				flush()
+			}
+		}
+	}
+}
+
+func (receiver *Receiver) push(ctx context.Context, frame string) bool {
+	line, err := ParseMessage(frame)
+
+	select {
+	case receiver.messages <- message{line: line, err: err}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (receiver *Receiver) readPackets(ctx context.Context, conn net.PacketConn) {
+	buffer := make([]byte, maxMessageSize)
+
+	for {
+		size, _, err := conn.ReadFrom(buffer)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+
+		if err != nil {
+			log.Printf("Ошибка чтения syslog: %v", err)
+			continue
+		}
+
+		if size == 0 {
+			continue
+		}
+
+		if !receiver.push(ctx, string(buffer[:size])) {
+			return
+		}
+	}
+}
+
+func (receiver *Receiver) accept(ctx context.Context, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+
+		if err != nil {
+			log.Printf("Ошибка подключения к syslog: %v", err)
+			time.Sleep(acceptDelay)
+
+			continue
+		}
+
+		go receiver.readStream(ctx, conn)
+	}
+}
+
+func (receiver *Receiver) readStream(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	reader := bufio.NewReaderSize(conn, maxMessageSize)
+
+	for {
+		frame, err := readFrame(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				log.Printf("Ошибка чтения syslog от %s: %v", conn.RemoteAddr(), err)
+			}
+
+			return
+		}
+
+		if frame == "" || frame == "\n" || frame == "\r\n" {
+			continue
+		}
+
+		if !receiver.push(ctx, frame) {
+			return
+		}
+	}
+}
+
+func readFrame(reader *bufio.Reader) (string, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	if first[0] < '1' || first[0] > '9' {
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return "", fmt.Errorf("сообщение syslog длиннее %d байт", maxMessageSize)
+		}
+
+		if err != nil && len(line) == 0 {
+			return "", err
+		}
+
+		return string(line), nil
+	}
+
+	prefix, err := reader.ReadSlice(' ')
+	if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+		return "", err
+	}
+
+	size, err := strconv.Atoi(string(prefix[:len(prefix)-1]))
+	if err != nil || len(prefix) > maxFrameDigits+1 || size > maxMessageSize {
+		return "", fmt.Errorf("неверная длина кадра syslog: %.10q", prefix)
+	}
+
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		return "", err
+	}
+
+	return string(frame), nil
+}
@@ -0,0 +1,95 @@
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiver(t *testing.T) {
+	receiver, err := Listen([]domain.SyslogListener{
+		{Network: domain.SyslogUDP, Address: "127.0.0.1:0"},
+		{Network: domain.SyslogTCP, Address: "127.0.0.1:0"},
+	}, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lines := make(chan string, 10)
+	errs := make(chan error, 10)
+
+	go receiver.Serve(ctx, func(batch []string, batchErrs []error) {
+		for _, line := range batch {
+			lines <- line
+		}
+
+		for _, err := range batchErrs {
+			errs <- err
+		}
+	})
+
+	addrs := receiver.Addrs()
+	require.Len(t, addrs, 2)
+
+	udp, err := net.Dial("udp", addrs[0].String())
+	require.NoError(t, err)
+
+	defer udp.Close()
+
+	_, err = udp.Write([]byte("<190>Oct 12 14:32:00 web1 nginx: udp"))
+	require.NoError(t, err)
+
+	tcp, err := net.Dial("tcp", addrs[1].String())
+	require.NoError(t, err)
+
+	defer tcp.Close()
+
+	framed := "<190>1 2023-10-12T14:32:00Z web1 nginx - - - octet\ncounting"
+	_, err = fmt.Fprintf(tcp, "%d %s<190>Oct 12 14:32:00 web1 nginx: newline\n\ngarbage\n", len(framed), framed)
+	require.NoError(t, err)
+
+	received := make([]string, 0, 3)
+
+	for len(received) < 3 {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "Сообщения syslog не получены", "получено: %v", received)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"udp", "octet\ncounting", "newline"}, received)
+
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "без приоритета")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "Ошибка разбора сообщения syslog не получена")
+	}
+
+	cancel()
+
+	require.Eventually(t, func() bool {
+		_, err := net.Dial("tcp", addrs[1].String())
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond, "Порт syslog должен закрываться после остановки")
+}
+
+func TestListen_AddressInUse(t *testing.T) {
+	receiver, err := Listen([]domain.SyslogListener{{Network: domain.SyslogTCP, Address: "127.0.0.1:0"}}, time.Second)
+	require.NoError(t, err)
+
+	defer receiver.Close()
+
+	_, err = Listen([]domain.SyslogListener{{Network: domain.SyslogTCP, Address: receiver.Addrs()[0].String()}}, time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package useragent
+
+import (
+	domain "analyzer/internal/domain"
+	lru "analyzer/pkg/lru"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+)
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+
+	cacheSize = 10_000
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+var (
+	defaultClassifier     *Classifier
+	defaultClassifierOnce sync.Once
+)
+
+type ruleSet struct {
+	Bots     []ruleSpec `json:"bots"`
+	Browsers []ruleSpec `json:"browsers"`
+	Systems  []ruleSpec `json:"os"`
+	Devices  []ruleSpec `json:"devices"`
+}
+
+type ruleSpec struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+type Classifier struct {
+	bots     []rule
+	browsers []rule
+	systems  []rule
+	devices  []rule
+
+	mu    sync.Mutex
+	cache *lru.Cache[string, domain.UserAgent]
+}
+
+func NewClassifier(data []byte) (*Classifier, error) {
+	var rules ruleSet
+
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать правила user-agent: %v", err)
+	}
+
+	classifier := &Classifier{cache: lru.New[string, domain.UserAgent](cacheSize)}
+
+	groups := []struct {
+		specs  []ruleSpec
+		target *[]rule
+	}{
+		{rules.Bots, &classifier.bots},
+		{rules.Browsers, &classifier.browsers},
+		{rules.Systems, &classifier.systems},
+		{rules.Devices, &classifier.devices},
+	}
+
+	for _, group := range groups {
+		for _, spec := range group.specs {
+			pattern, err := regexp.Compile(spec.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("не удалась компиляция правила user-agent %s: %v", spec.Name, err)
+			}
+
+			*group.target = append(*group.target, rule{name: spec.Name, pattern: pattern})
+		}
+	}
+
+	return classifier, nil
+}
+
+func LoadClassifier(path string) (*Classifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл правил user-agent %s: %v", path, err)
+	}
+
+	return NewClassifier(data)
+}
+
+func DefaultClassifier() *Classifier {
+	defaultClassifierOnce.Do(func() {
+		classifier, err := NewClassifier(defaultRules)
+		if err != nil {
+			panic(err)
+		}
+
+		defaultClassifier = classifier
+	})
+
+	return defaultClassifier
+}
+
+func (classifier *Classifier) Classify(agent string) domain.UserAgent {
+	classifier.mu.Lock()
+	defer classifier.mu.Unlock()
+
+	if result, exists := classifier.cache.Get(agent); exists {
+		return result
+	}
+
+	result := classifier.classify(agent)
+	classifier.cache.Add(agent, result)
+
+	return result
+}
+
+func (classifier *Classifier) classify(agent string) domain.UserAgent {
+	result := domain.UserAgent{Device: DeviceDesktop}
+
+	if bot, _, ok := match(classifier.bots, agent); ok {
+		result.Bot = true
+		result.BotName = bot
+		result.Device = DeviceBot
+	}
+
+	result.Browser, result.BrowserVersion, _ = match(classifier.browsers, agent)
+	result.OS, result.OSVersion, _ = match(classifier.systems, agent)
+
+	if device, _, ok := match(classifier.devices, agent); ok && !result.Bot {
+		result.Device = device
+	}
+
+	return result
+}
+
+func match(rules []rule, agent string) (name, version string, ok bool) {
+	for _, rule := range rules {
+		matches := rule.pattern.FindStringSubmatch(agent)
+		if matches == nil {
+			continue
+		}
+
+		for _, group := range matches[1:] {
+			if group != "" {
+				version = group
+				break
+			}
+		}
+
+		return rule.name, version, true
+	}
+
+	return "", "", false
+}
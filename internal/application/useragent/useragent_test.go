@@ -0,0 +1,98 @@
+package useragent
+
+import (
+	"fmt"
+	"testing"
+
+	domain "analyzer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	classifier := DefaultClassifier()
+
+	t.Run("DesktopChrome", func(t *testing.T) {
+		agent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) " +
+			"Chrome/120.0.0.0 Safari/537.36"
+
+		assert.Equal(t, domain.UserAgent{
+			Browser:        "Chrome",
+			BrowserVersion: "120",
+			OS:             "Windows",
+			OSVersion:      "10.0",
+			Device:         DeviceDesktop,
+		}, classifier.Classify(agent))
+	})
+
+	t.Run("MobileSafari", func(t *testing.T) {
+		agent := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) " +
+			"Version/17.1 Mobile/15E148 Safari/604.1"
+
+		result := classifier.Classify(agent)
+
+		assert.Equal(t, "Safari", result.Browser)
+		assert.Equal(t, "17", result.BrowserVersion)
+		assert.Equal(t, "iOS", result.OS)
+		assert.Equal(t, "mobile", result.Device)
+		assert.False(t, result.Bot)
+	})
+
+	t.Run("Edge", func(t *testing.T) {
+		agent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) " +
+			"Chrome/119.0.0.0 Safari/537.36 Edg/119.0.2151.97"
+
+		assert.Equal(t, "Edge", classifier.Classify(agent).Browser)
+	})
+
+	t.Run("Bots", func(t *testing.T) {
+		bots := map[string]string{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)": "Googlebot",
+			"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)":  "Bingbot",
+			"curl/8.4.0":                "curl",
+			"python-requests/2.31.0":    "python-requests",
+			"Go-http-client/1.1":        "Go-http-client",
+			"SomeCustomCrawler/1.0":     "Other bot",
+			"Debian APT-HTTP/1.3 (2.6)": "",
+		}
+
+		for agent, name := range bots {
+			result := classifier.Classify(agent)
+
+			assert.Equal(t, name != "", result.Bot, agent)
+			assert.Equal(t, name, result.BotName, agent)
+		}
+	})
+}
+
+func TestNewClassifier(t *testing.T) {
+	t.Run("CustomRules", func(t *testing.T) {
+		classifier, err := NewClassifier([]byte(`{"bots": [{"name": "Internal", "pattern": "^monitoring-agent"}]}`))
+		require.NoError(t, err)
+
+		assert.True(t, classifier.Classify("monitoring-agent/1.0").Bot)
+		assert.False(t, classifier.Classify("Googlebot/2.1").Bot)
+	})
+
+	t.Run("BoundedCache", func(t *testing.T) {
+		classifier, err := NewClassifier(defaultRules)
+		require.NoError(t, err)
+
+		for ind := range cacheSize + 100 {
+			classifier.Classify(fmt.Sprintf("Mozilla/5.0 client-%d", ind))
+		}
+
+		assert.Equal(t, cacheSize, classifier.cache.Len())
+	})
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		_, err := NewClassifier([]byte(`{"browsers": [{"name": "Broken", "pattern": "[invalid"}]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := NewClassifier([]byte(`not json`))
+		assert.Error(t, err)
+	})
+}
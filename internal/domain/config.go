@@ -1,31 +1,145 @@
 package domain
 
 import (
+	"bufio"
 	"fmt"
 	"log"
+	"net"
+	"net/netip"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
+	DefaultBucketSize = time.Hour
+
+	DefaultSessionGap   = 30 * time.Minute
+	DefaultFunnelWindow = time.Hour
+
+	minApproxCapacity = 10
+
+	DefaultWorkers = 1
+
+	DefaultAnomalyThreshold = 3.5
+	DefaultAnomalyWindow    = 24
+
+	ClientIPRemote    = "remote"
+	ClientIPForwarded = "forwarded"
+	ClientIPRealIP    = "real-ip"
+
+	AnonymizeTruncate = "truncate"
+	AnonymizeHMAC     = "hmac"
+
+	ExportSQLite  = "sqlite"
+	ExportParquet = "parquet"
+
+	PartitionDate       = "date"
+	DefaultRowGroupSize = 100_000
+	MinRowGroupSize     = 1000
+
+	DefaultRequestTimeout  = time.Minute
+	DefaultMaxUpload       = 100 << 20
+	DefaultRefresh         = 5 * time.Second
+	DefaultMaxRecords      = 1_000_000
+	DefaultDashboardListen = "localhost:8080"
+	DefaultExporterListen  = ":9113"
+
+	SyslogUDP = "udp"
+	SyslogTCP = "tcp"
+
+	LabelMethod        = "method"
+	LabelStatus        = "status"
+	LabelRoute         = "route"
+	DefaultMethodLimit = 20
+	DefaultStatusLimit = 60
+	DefaultRouteLimit  = 200
+
+	ExportCreate  = "create"
+	ExportAppend  = "append"
+	ExportReplace = "replace"
+
 	MarkdownFormat = "markdown"
 	AdocFormat     = "adoc"
+	JSONFormat     = "json"
 	DefaultFormat  = MarkdownFormat
 )
 
 type Config struct {
 	Path        string
 	TypePath    string
+	States      []string
+	EmitState   string
+	Checkpoint  string
+	Store       string
+	Retention   RetentionOptions
+	Export      ExportOptions
+	Serve       ServeOptions
+	Syslog      []SyslogListener
+	LabelLimits map[string]int
 	From        time.Time
 	To          time.Time
 	Format      string
 	FilterField string
 	FilterValue *regexp.Regexp
+	URLOptions  URLOptions
+
+	UserAgentRules string
+	SecurityRules  string
+	GeoIPDatabases []string
+	ClientIP       ClientIPOptions
+	Anonymize      AnonymizeOptions
+	ProfileClient  netip.Prefix
+	Approx         int
+	Workers        int
+	SiteHosts      []string
+	BucketSize     time.Duration
+	SessionGap     time.Duration
+	Funnel         []string
+	FunnelWindow   time.Duration
+	Anomaly        AnomalyOptions
+}
+
+type ClientIPOptions struct {
+	Mode           string
+	TrustedProxies []netip.Prefix
+}
+
+type AnonymizeOptions struct {
+	Mode         string
+	Key          []byte
+	RedactParams *regexp.Regexp
+}
+
+type ExportOptions struct {
+	Format       string
+	Output       string
+	Mode         string
+	RowGroupSize int
+	Partition    string
+}
+
+type ServeOptions struct {
+	Listen     string
+	Timeout    time.Duration
+	MaxUpload  int64
+	Refresh    time.Duration
+	MaxRecords int
+}
+
+type SyslogListener struct {
+	Network string
+	Address string
+}
+
+type AnomalyOptions struct {
+	Threshold float64
+	Window    int
 }
 
 func (config *Config) AddPath(path string) error {
@@ -45,6 +159,343 @@ func (config *Config) AddPath(path string) error {
 	return nil
 }
 
+func (config *Config) AddSource(path string) error {
+	if strings.HasSuffix(path, ".json") {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			config.Path = path
+			config.TypePath = "report"
+
+			return nil
+		}
+	}
+
+	if strings.HasSuffix(path, ".state") {
+		if matches, _ := filepath.Glob(path); len(matches) > 0 {
+			config.Path = path
+
+			return config.AddStates(matches)
+		}
+	}
+
+	return config.AddPath(path)
+}
+
+func (config *Config) AddServeSource(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	return config.AddPath(path)
+}
+
+func (config *Config) AddStates(paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("не указаны файлы состояния для объединения")
+	}
+
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			return fmt.Errorf("неверный путь к файлу состояния: %s", path)
+		}
+	}
+
+	config.States = paths
+	config.TypePath = "state"
+
+	return nil
+}
+
+func (config *Config) AddEmitState(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return fmt.Errorf("неверный путь для --emit-state: каталог %s не существует", filepath.Dir(path))
+	}
+
+	if config.TypePath == "report" {
+		return fmt.Errorf("--emit-state нельзя использовать с готовым отчётом")
+	}
+
+	config.EmitState = path
+
+	return nil
+}
+
+func (config *Config) AddCheckpoint(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if config.TypePath != "local" {
+		return fmt.Errorf("--checkpoint поддерживается только для локальных файлов логов")
+	}
+
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return fmt.Errorf("неверный путь для --checkpoint: каталог %s не существует", filepath.Dir(path))
+	}
+
+	if config.Anonymize.Mode == AnonymizeHMAC && len(config.Anonymize.Key) == 0 {
+		return fmt.Errorf("для --checkpoint с --anonymize hmac нужен постоянный --anonymize-key")
+	}
+
+	config.Checkpoint = path
+
+	return nil
+}
+
+func (config *Config) AddStore(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return fmt.Errorf("неверный путь для --store: каталог %s не существует", filepath.Dir(path))
+	}
+
+	if config.Anonymize.Mode == AnonymizeHMAC && len(config.Anonymize.Key) == 0 {
+		return fmt.Errorf("для --store с --anonymize hmac нужен постоянный --anonymize-key")
+	}
+
+	config.Store = path
+
+	return nil
+}
+
+func (config *Config) AddRetention(keep string) error {
+	if keep == "" {
+		return nil
+	}
+
+	value, err := time.ParseDuration(keep)
+	if err != nil || value <= 0 {
+		return fmt.Errorf("неверный срок хранения для --retention: %s", keep)
+	}
+
+	config.Retention.Keep = value
+
+	return nil
+}
+
+func (config *Config) AddDownsample(after string) error {
+	if after == "" {
+		return nil
+	}
+
+	value, err := time.ParseDuration(after)
+	if err != nil || value <= 0 {
+		return fmt.Errorf("неверный интервал для --downsample: %s", after)
+	}
+
+	if config.Retention.Keep > 0 && value >= config.Retention.Keep {
+		return fmt.Errorf("--downsample должен быть меньше --retention")
+	}
+
+	config.Retention.Downsample = value
+
+	return nil
+}
+
+func (config *Config) AddExport(format, output string) error {
+	if format == "" {
+		return nil
+	}
+
+	if format != ExportSQLite && format != ExportParquet {
+		return fmt.Errorf("неподдерживаемый формат экспорта: %s", format)
+	}
+
+	if output == "" {
+		return fmt.Errorf("не указан файл для экспорта")
+	}
+
+	if info, err := os.Stat(filepath.Dir(output)); err != nil || !info.IsDir() {
+		return fmt.Errorf("неверный путь для экспорта: каталог %s не существует", filepath.Dir(output))
+	}
+
+	config.Export.Format = format
+	config.Export.Output = output
+
+	return nil
+}
+
+func (config *Config) AddExportMode(mode string) error {
+	switch mode {
+	case "":
+		config.Export.Mode = ExportCreate
+	case ExportCreate, ExportAppend, ExportReplace:
+		config.Export.Mode = mode
+	default:
+		return fmt.Errorf("неизвестный режим экспорта: %s", mode)
+	}
+
+	if mode == ExportAppend && config.Anonymize.Mode == AnonymizeHMAC && len(config.Anonymize.Key) == 0 {
+		return fmt.Errorf("для --mode append с --anonymize hmac нужен постоянный --anonymize-key")
+	}
+
+	return nil
+}
+
+func (config *Config) AddRowGroupSize(size string) error {
+	if size == "" {
+		config.Export.RowGroupSize = DefaultRowGroupSize
+		return nil
+	}
+
+	if config.Export.Format != ExportParquet {
+		return fmt.Errorf("--row-group поддерживается только для экспорта в parquet")
+	}
+
+	value, err := strconv.Atoi(size)
+	if err != nil || value < MinRowGroupSize {
+		return fmt.Errorf("неверный размер для --row-group, нужно целое число не меньше %d: %s", MinRowGroupSize, size)
+	}
+
+	config.Export.RowGroupSize = value
+
+	return nil
+}
+
+func (config *Config) AddPartition(partition string) error {
+	if partition == "" {
+		return nil
+	}
+
+	if config.Export.Format != ExportParquet {
+		return fmt.Errorf("--partition поддерживается только для экспорта в parquet")
+	}
+
+	if partition != PartitionDate {
+		return fmt.Errorf("неизвестное разбиение для --partition: %s", partition)
+	}
+
+	config.Export.Partition = partition
+
+	return nil
+}
+
+func (config *Config) AddListen(address string) error {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("неверный адрес для --listen: %s", address)
+	}
+
+	config.Serve.Listen = address
+
+	return nil
+}
+
+func (config *Config) AddRequestTimeout(timeout string) error {
+	if timeout == "" {
+		config.Serve.Timeout = DefaultRequestTimeout
+		return nil
+	}
+
+	value, err := time.ParseDuration(timeout)
+	if err != nil || value <= 0 {
+		return fmt.Errorf("неверное время ожидания для --timeout: %s", timeout)
+	}
+
+	config.Serve.Timeout = value
+
+	return nil
+}
+
+func (config *Config) AddMaxUpload(size string) error {
+	if size == "" {
+		config.Serve.MaxUpload = DefaultMaxUpload
+		return nil
+	}
+
+	value, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || value < 1 {
+		return fmt.Errorf("неверный размер для --max-upload, нужно целое положительное число мегабайт: %s", size)
+	}
+
+	config.Serve.MaxUpload = value << 20
+
+	return nil
+}
+
+func (config *Config) AddRefresh(interval string) error {
+	if interval == "" {
+		config.Serve.Refresh = DefaultRefresh
+		return nil
+	}
+
+	value, err := time.ParseDuration(interval)
+	if err != nil || value < time.Second {
+		return fmt.Errorf("неверный интервал обновления для --refresh, нужно не меньше 1s: %s", interval)
+	}
+
+	config.Serve.Refresh = value
+
+	return nil
+}
+
+func (config *Config) AddMaxRecords(limit string) error {
+	if limit == "" {
+		config.Serve.MaxRecords = DefaultMaxRecords
+		return nil
+	}
+
+	value, err := strconv.Atoi(limit)
+	if err != nil || value < 1 {
+		return fmt.Errorf("неверное число записей для --max-records, нужно целое положительное число: %s", limit)
+	}
+
+	config.Serve.MaxRecords = value
+
+	return nil
+}
+
+func (config *Config) AddSyslog(listeners string) error {
+	for _, item := range splitList(listeners) {
+		network, address, _ := strings.Cut(item, "://")
+
+		if network != SyslogUDP && network != SyslogTCP {
+			return fmt.Errorf("неверный адрес для --syslog, ожидается udp://хост:порт или tcp://хост:порт: %s", item)
+		}
+
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return fmt.Errorf("неверный адрес для --syslog, ожидается udp://хост:порт или tcp://хост:порт: %s", item)
+		}
+
+		config.Syslog = append(config.Syslog, SyslogListener{Network: network, Address: address})
+	}
+
+	if len(config.Syslog) > 0 && config.Path != "" {
+		return fmt.Errorf("укажите либо --path, либо --syslog")
+	}
+
+	return nil
+}
+
+func (config *Config) AddLabelLimits(limits string) error {
+	config.LabelLimits = map[string]int{
+		LabelMethod: DefaultMethodLimit,
+		LabelStatus: DefaultStatusLimit,
+		LabelRoute:  DefaultRouteLimit,
+	}
+
+	for _, item := range splitList(limits) {
+		label, value, _ := strings.Cut(item, "=")
+
+		if _, known := config.LabelLimits[label]; !known {
+			return fmt.Errorf("неизвестная метка для --label-limits: %s", label)
+		}
+
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return fmt.Errorf("неверное ограничение для --label-limits, ожидается метка=число: %s", item)
+		}
+
+		config.LabelLimits[label] = limit
+	}
+
+	return nil
+}
+
 func (config *Config) AddFrom(from string) error {
 	var err error
 
@@ -81,6 +532,8 @@ func (config *Config) AddFormat(format string) error {
 		config.Format = AdocFormat
 	case MarkdownFormat:
 		config.Format = MarkdownFormat
+	case JSONFormat:
+		config.Format = JSONFormat
 	case "":
 		config.Format = DefaultFormat
 	default:
@@ -115,8 +568,403 @@ func (config *Config) AddFilterValue(value string) error {
 	return nil
 }
 
+func (config *Config) AddNormalize(options string) error {
+	for _, option := range splitList(options) {
+		switch option {
+		case NormalizeLowercase:
+			config.URLOptions.Lowercase = true
+		case NormalizeDecode:
+			config.URLOptions.Decode = true
+		case NormalizeIDs:
+			config.URLOptions.CollapseIDs = true
+		default:
+			return fmt.Errorf("неизвестная опция нормализации: %s", option)
+		}
+	}
+
+	return nil
+}
+
+func (config *Config) AddStripQuery(params string) error {
+	if len(config.URLOptions.KeepQuery) > 0 && params != "" {
+		return fmt.Errorf("флаги --strip-query и --keep-query не могут быть указаны одновременно")
+	}
+
+	config.URLOptions.StripQuery = splitList(params)
+
+	return nil
+}
+
+func (config *Config) AddKeepQuery(params string) error {
+	if len(config.URLOptions.StripQuery) > 0 && params != "" {
+		return fmt.Errorf("флаги --strip-query и --keep-query не могут быть указаны одновременно")
+	}
+
+	config.URLOptions.KeepQuery = splitList(params)
+
+	return nil
+}
+
+func (config *Config) AddRoutes(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл маршрутов %s: %v", path, err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		route, err := NewRoute(line)
+		if err != nil {
+			return err
+		}
+
+		config.URLOptions.Routes = append(config.URLOptions.Routes, route)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("не удалось прочитать файл маршрутов %s: %v", path, err)
+	}
+
+	return nil
+}
+
+func (config *Config) AddUserAgentRules(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("не найден файл правил user-agent %s: %v", path, err)
+	}
+
+	config.UserAgentRules = path
+
+	return nil
+}
+
+func (config *Config) AddSecurityRules(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("не найден файл правил безопасности %s: %v", path, err)
+	}
+
+	config.SecurityRules = path
+
+	return nil
+}
+
+func (config *Config) AddGeoIPDatabases(paths string) error {
+	config.GeoIPDatabases = make([]string, 0)
+
+	for _, path := range splitList(paths) {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("не найдена база GeoIP %s: %v", path, err)
+		}
+
+		config.GeoIPDatabases = append(config.GeoIPDatabases, path)
+	}
+
+	return nil
+}
+
+func (config *Config) AddTrustedProxies(proxies string) error {
+	config.ClientIP.TrustedProxies = make([]netip.Prefix, 0)
+
+	for _, proxy := range splitList(proxies) {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return fmt.Errorf("неверная подсеть доверенного прокси: %s", proxy)
+		}
+
+		config.ClientIP.TrustedProxies = append(config.ClientIP.TrustedProxies, prefix)
+	}
+
+	return nil
+}
+
+func (config *Config) AddClientIPMode(mode string) error {
+	switch mode {
+	case "", ClientIPRemote:
+		config.ClientIP.Mode = ClientIPRemote
+		return nil
+	case ClientIPForwarded, ClientIPRealIP:
+	default:
+		return fmt.Errorf("неизвестный режим --client-ip: %s", mode)
+	}
+
+	if len(config.ClientIP.TrustedProxies) == 0 {
+		return fmt.Errorf("для --client-ip %s нужно указать --trusted-proxies", mode)
+	}
+
+	config.ClientIP.Mode = mode
+
+	return nil
+}
+
+func (config *Config) AddProfileClient(client string) error {
+	if client == "" {
+		return nil
+	}
+
+	prefix, err := parsePrefix(client)
+	if err != nil {
+		return fmt.Errorf("неверный адрес или подсеть для --profile-client: %s", client)
+	}
+
+	config.ProfileClient = prefix
+
+	return nil
+}
+
+func (config *Config) AddApprox(capacity string) error {
+	if capacity == "" {
+		return nil
+	}
+
+	value, err := strconv.Atoi(capacity)
+	if err != nil || value < minApproxCapacity {
+		return fmt.Errorf("неверный размер для --approx, нужно целое число не меньше %d: %s", minApproxCapacity, capacity)
+	}
+
+	config.Approx = value
+
+	return nil
+}
+
+func (config *Config) AddWorkers(workers string) error {
+	if workers == "" {
+		config.Workers = DefaultWorkers
+		return nil
+	}
+
+	value, err := strconv.Atoi(workers)
+	if err != nil || value < 1 {
+		return fmt.Errorf("неверное число потоков для --workers, нужно целое положительное число: %s", workers)
+	}
+
+	config.Workers = value
+
+	return nil
+}
+
+func (config *Config) AddAnonymize(mode string) error {
+	switch mode {
+	case "", AnonymizeTruncate, AnonymizeHMAC:
+		config.Anonymize.Mode = mode
+		return nil
+	default:
+		return fmt.Errorf("неизвестный режим --anonymize: %s", mode)
+	}
+}
+
+func (config *Config) AddAnonymizeKey(key string) error {
+	if key == "" {
+		return config.checkAnonymizeKey()
+	}
+
+	if config.Anonymize.Mode == "" {
+		return fmt.Errorf("--anonymize-key задан без --anonymize")
+	}
+
+	config.Anonymize.Key = []byte(key)
+
+	return nil
+}
+
+func (config *Config) checkAnonymizeKey() error {
+	if config.Anonymize.Mode != AnonymizeHMAC {
+		return nil
+	}
+
+	switch {
+	case config.EmitState != "":
+		return fmt.Errorf("для --emit-state с --anonymize hmac нужен постоянный --anonymize-key")
+	case config.Serve.Listen != "":
+		return fmt.Errorf("для HTTP-сервера с --anonymize hmac нужен постоянный --anonymize-key")
+	case config.Approx > 0:
+		return fmt.Errorf("для --approx с --anonymize hmac нужен постоянный --anonymize-key")
+	}
+
+	return nil
+}
+
+func (config *Config) AddRedactParams(params string) error {
+	if params == "" {
+		return nil
+	}
+
+	pattern, err := regexp.Compile(params)
+	if err != nil {
+		return fmt.Errorf("неверное регулярное выражение для --redact-params: %v", err)
+	}
+
+	config.Anonymize.RedactParams = pattern
+
+	return nil
+}
+
+func (config *Config) AddSiteHosts(hosts string) error {
+	config.SiteHosts = make([]string, 0)
+
+	for _, host := range splitList(hosts) {
+		if strings.ContainsAny(host, "/:") {
+			return fmt.Errorf("неверное имя хоста сайта: %s", host)
+		}
+
+		config.SiteHosts = append(config.SiteHosts, strings.TrimPrefix(strings.ToLower(host), "www."))
+	}
+
+	return nil
+}
+
+func (config *Config) AddBucketSize(size string) error {
+	if size == "" {
+		config.BucketSize = DefaultBucketSize
+		return nil
+	}
+
+	duration, err := time.ParseDuration(size)
+	if err != nil {
+		return fmt.Errorf("неверный размер интервала для --bucket: %v", err)
+	}
+
+	if duration < time.Second || duration%time.Second != 0 {
+		return fmt.Errorf("размер интервала для --bucket должен быть целым числом секунд: %s", size)
+	}
+
+	config.BucketSize = duration
+
+	return nil
+}
+
+func (config *Config) AddSessionGap(gap string) error {
+	if gap == "" {
+		config.SessionGap = DefaultSessionGap
+		return nil
+	}
+
+	duration, err := time.ParseDuration(gap)
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("неверный интервал неактивности для --session-gap: %s", gap)
+	}
+
+	config.SessionGap = duration
+
+	return nil
+}
+
+func (config *Config) AddFunnel(steps string) error {
+	config.Funnel = splitList(steps)
+
+	if len(config.Funnel) == 1 {
+		return fmt.Errorf("воронка в --funnel должна содержать хотя бы два шага: %s", steps)
+	}
+
+	if len(config.Funnel) > 0 && config.Approx > 0 {
+		return fmt.Errorf("--funnel не поддерживается вместе с --approx: воронка хранит все визиты клиентов")
+	}
+
+	for _, step := range config.Funnel {
+		if !strings.HasPrefix(step, "/") {
+			return fmt.Errorf("шаг воронки в --funnel должен начинаться с /: %s", step)
+		}
+	}
+
+	return nil
+}
+
+func (config *Config) AddFunnelWindow(window string) error {
+	if window == "" {
+		config.FunnelWindow = DefaultFunnelWindow
+		return nil
+	}
+
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("неверное окно воронки для --funnel-window: %s", window)
+	}
+
+	config.FunnelWindow = duration
+
+	return nil
+}
+
+func (config *Config) AddAnomalyThreshold(threshold string) error {
+	if threshold == "" {
+		config.Anomaly.Threshold = DefaultAnomalyThreshold
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(threshold, 64)
+	if err != nil || value <= 0 {
+		return fmt.Errorf("неверный порог для --anomaly-threshold: %s", threshold)
+	}
+
+	config.Anomaly.Threshold = value
+
+	return nil
+}
+
+func (config *Config) AddAnomalyWindow(window string) error {
+	if window == "" {
+		config.Anomaly.Window = DefaultAnomalyWindow
+		return nil
+	}
+
+	value, err := strconv.Atoi(window)
+	if err != nil || value < 2 {
+		return fmt.Errorf("неверный размер окна для --anomaly-window: %s", window)
+	}
+
+	config.Anomaly.Window = value
+
+	return nil
+}
+
 func (config *Config) getFilterFields() []string {
-	return []string{"agent", "address", "user", "method", "url", "protocol", "status", "referer"}
+	return []string{"agent", "address", "user", "method", "url", "protocol", "status", "referer", "bot", "country", "asn", "peer"}
+}
+
+func parsePrefix(value string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
 }
 
 func isValidURL(path string) bool {
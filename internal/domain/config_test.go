@@ -3,7 +3,9 @@ package domain
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -147,3 +149,353 @@ func TestFilterHandling(t *testing.T) {
 		assert.Error(t, err, "Ожидалось, что выкинется ошибка для некорректного регулярного выражения")
 	})
 }
+
+func TestURLOptions(t *testing.T) {
+	t.Run("ValidNormalize", func(t *testing.T) {
+		config := &Config{}
+		err := config.AddNormalize("lowercase, ids")
+
+		assert.NoError(t, err)
+		assert.True(t, config.URLOptions.Lowercase)
+		assert.True(t, config.URLOptions.CollapseIDs)
+		assert.False(t, config.URLOptions.Decode)
+	})
+
+	t.Run("InvalidNormalize", func(t *testing.T) {
+		config := &Config{}
+		err := config.AddNormalize("uppercase")
+		assert.Error(t, err, "Ожидалось, что выкинется ошибка для неизвестной опции нормализации")
+	})
+
+	t.Run("StripAndKeepQuery", func(t *testing.T) {
+		config := &Config{}
+
+		assert.NoError(t, config.AddStripQuery("utm_source,utm_medium"))
+		assert.Equal(t, []string{"utm_source", "utm_medium"}, config.URLOptions.StripQuery)
+		assert.Error(t, config.AddKeepQuery("page"), "Ожидалось, что выкинется ошибка для одновременных --strip-query и --keep-query")
+	})
+
+	t.Run("Routes", func(t *testing.T) {
+		config := &Config{}
+		tmpFile := createTempFile(t)
+
+		defer os.Remove(tmpFile)
+
+		require.NoError(t, os.WriteFile(tmpFile, []byte("# маршруты\n/users/{id}\n\n/static/*\n"), 0o600))
+
+		err := config.AddRoutes(tmpFile)
+
+		require.NoError(t, err)
+		require.Len(t, config.URLOptions.Routes, 2)
+		assert.True(t, config.URLOptions.Routes[0].Pattern.MatchString("/users/42"))
+		assert.False(t, config.URLOptions.Routes[0].Pattern.MatchString("/users/42/orders"))
+		assert.True(t, config.URLOptions.Routes[1].Pattern.MatchString("/static/js/app.js"))
+	})
+}
+
+func TestAddBucketSize(t *testing.T) {
+	config := &Config{}
+
+	assert.NoError(t, config.AddBucketSize(""))
+	assert.Equal(t, DefaultBucketSize, config.BucketSize)
+
+	assert.NoError(t, config.AddBucketSize("15m"))
+	assert.Equal(t, 15*time.Minute, config.BucketSize)
+
+	assert.Error(t, config.AddBucketSize("1500ms"), "Ожидалось, что выкинется ошибка для нецелого числа секунд")
+	assert.Error(t, config.AddBucketSize("hour"), "Ожидалось, что выкинется ошибка для некорректного интервала")
+}
+
+func TestAddSessionGap(t *testing.T) {
+	config := &Config{}
+
+	assert.NoError(t, config.AddSessionGap(""))
+	assert.Equal(t, DefaultSessionGap, config.SessionGap)
+
+	assert.NoError(t, config.AddSessionGap("10m"))
+	assert.Equal(t, 10*time.Minute, config.SessionGap)
+
+	assert.Error(t, config.AddSessionGap("-5m"), "Ожидалось, что выкинется ошибка для отрицательного интервала")
+	assert.Error(t, config.AddSessionGap("soon"), "Ожидалось, что выкинется ошибка для некорректного интервала")
+}
+
+func TestAddFunnel(t *testing.T) {
+	config := &Config{}
+
+	assert.NoError(t, config.AddFunnel("/cart, /checkout,/order/complete"))
+	assert.Equal(t, []string{"/cart", "/checkout", "/order/complete"}, config.Funnel)
+
+	assert.NoError(t, config.AddFunnelWindow(""))
+	assert.Equal(t, DefaultFunnelWindow, config.FunnelWindow)
+
+	assert.Error(t, config.AddFunnel("/cart"), "Ожидалось, что выкинется ошибка для воронки из одного шага")
+	assert.Error(t, config.AddFunnel("/cart,checkout"), "Ожидалось, что выкинется ошибка для шага без /")
+	assert.Error(t, config.AddFunnelWindow("0s"), "Ожидалось, что выкинется ошибка для нулевого окна")
+}
+
+func TestClientIPOptions(t *testing.T) {
+	t.Run("ForwardedMode", func(t *testing.T) {
+		config := &Config{}
+
+		require.NoError(t, config.AddTrustedProxies("10.0.0.0/8, 192.168.1.10"))
+		require.NoError(t, config.AddClientIPMode(ClientIPForwarded))
+
+		assert.Equal(t, ClientIPForwarded, config.ClientIP.Mode)
+		assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.10/32")},
+			config.ClientIP.TrustedProxies)
+	})
+
+	t.Run("DefaultMode", func(t *testing.T) {
+		config := &Config{}
+
+		require.NoError(t, config.AddClientIPMode(""))
+		assert.Equal(t, ClientIPRemote, config.ClientIP.Mode)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		config := &Config{}
+
+		assert.Error(t, config.AddTrustedProxies("10.0.0.0/33"), "Ожидалось, что выкинется ошибка для неверной подсети")
+		assert.Error(t, config.AddClientIPMode(ClientIPRealIP), "Ожидалось, что выкинется ошибка без --trusted-proxies")
+		assert.Error(t, config.AddClientIPMode("header"), "Ожидалось, что выкинется ошибка для неизвестного режима")
+	})
+}
+
+func TestAnonymizeOptions(t *testing.T) {
+	config := &Config{}
+
+	assert.Error(t, config.AddAnonymizeKey("secret"), "Ожидалось, что выкинется ошибка для ключа без режима")
+	assert.Error(t, config.AddAnonymize("mask"), "Ожидалось, что выкинется ошибка для неизвестного режима")
+	assert.Error(t, config.AddRedactParams("(token"), "Ожидалось, что выкинется ошибка для некорректного выражения")
+
+	require.NoError(t, config.AddAnonymize(AnonymizeHMAC))
+	require.NoError(t, config.AddAnonymizeKey("secret"))
+	require.NoError(t, config.AddRedactParams("^(token|email)$"))
+
+	assert.Equal(t, AnonymizeHMAC, config.Anonymize.Mode)
+	assert.Equal(t, []byte("secret"), config.Anonymize.Key)
+	assert.True(t, config.Anonymize.RedactParams.MatchString("email"))
+}
+
+func TestAnonymizeKeyRequired(t *testing.T) {
+	stateful := map[string]func(config *Config) error{
+		"EmitState": func(config *Config) error {
+			config.EmitState = "state.json"
+			require.NoError(t, config.AddAnonymize(AnonymizeHMAC))
+
+			return config.AddAnonymizeKey("")
+		},
+		"Serve": func(config *Config) error {
+			config.Serve.Listen = DefaultDashboardListen
+			require.NoError(t, config.AddAnonymize(AnonymizeHMAC))
+
+			return config.AddAnonymizeKey("")
+		},
+		"ExportAppend": func(config *Config) error {
+			require.NoError(t, config.AddAnonymize(AnonymizeHMAC))
+			return config.AddExportMode(ExportAppend)
+		},
+		"Store": func(config *Config) error {
+			require.NoError(t, config.AddAnonymize(AnonymizeHMAC))
+			return config.AddStore(filepath.Join(t.TempDir(), "store.db"))
+		},
+	}
+
+	for name, apply := range stateful {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, apply(&Config{}), "--anonymize-key")
+		})
+	}
+
+	config := &Config{EmitState: "state.json"}
+
+	require.NoError(t, config.AddAnonymize(AnonymizeTruncate))
+	require.NoError(t, config.AddAnonymizeKey(""))
+	require.NoError(t, config.AddExportMode(ExportAppend))
+}
+
+func TestAddProfileClient(t *testing.T) {
+	config := &Config{}
+
+	require.NoError(t, config.AddProfileClient("203.0.113.7"))
+	assert.Equal(t, netip.MustParsePrefix("203.0.113.7/32"), config.ProfileClient)
+
+	require.NoError(t, config.AddProfileClient("203.0.113.77/24"))
+	assert.Equal(t, netip.MustParsePrefix("203.0.113.0/24"), config.ProfileClient)
+
+	assert.Error(t, config.AddProfileClient("example.com"), "Ожидалось, что выкинется ошибка для имени хоста")
+}
+
+func TestAddApprox(t *testing.T) {
+	config := &Config{}
+
+	require.NoError(t, config.AddApprox(""))
+	assert.Zero(t, config.Approx)
+
+	require.NoError(t, config.AddApprox("1000"))
+	assert.Equal(t, 1000, config.Approx)
+
+	assert.Error(t, config.AddApprox("5"), "Ожидалось, что выкинется ошибка для слишком маленького размера")
+	assert.Error(t, config.AddApprox("many"), "Ожидалось, что выкинется ошибка для нечислового размера")
+
+	assert.Error(t, config.AddFunnel("/cart,/checkout"), "Ожидалось, что выкинется ошибка для воронки с --approx")
+	require.NoError(t, config.AddFunnel(""))
+
+	require.NoError(t, config.AddAnonymize(AnonymizeHMAC))
+	assert.Error(t, config.AddAnonymizeKey(""), "Ожидалось, что выкинется ошибка для HMAC без ключа с --approx")
+}
+
+func TestAddWorkers(t *testing.T) {
+	config := &Config{}
+
+	require.NoError(t, config.AddWorkers(""))
+	assert.Equal(t, DefaultWorkers, config.Workers)
+
+	require.NoError(t, config.AddWorkers("8"))
+	assert.Equal(t, 8, config.Workers)
+
+	assert.Error(t, config.AddWorkers("0"), "Ожидалось, что выкинется ошибка для нулевого числа потоков")
+	assert.Error(t, config.AddWorkers("all"), "Ожидалось, что выкинется ошибка для нечислового значения")
+}
+
+func TestServeOptions(t *testing.T) {
+	config := &Config{}
+
+	require.NoError(t, config.AddServeSource(""))
+	assert.Empty(t, config.TypePath)
+
+	require.NoError(t, config.AddListen(":8080"))
+	require.NoError(t, config.AddRequestTimeout(""))
+	require.NoError(t, config.AddMaxUpload("16"))
+	assert.Equal(t, ServeOptions{Listen: ":8080", Timeout: DefaultRequestTimeout, MaxUpload: 16 << 20}, config.Serve)
+
+	assert.Error(t, config.AddListen("8080"), "Ожидалось, что выкинется ошибка для адреса без порта")
+	assert.Error(t, config.AddRequestTimeout("-1s"), "Ожидалось, что выкинется ошибка для отрицательного времени")
+	assert.Error(t, config.AddMaxUpload("0"), "Ожидалось, что выкинется ошибка для нулевого размера")
+
+	require.NoError(t, config.AddRefresh(""))
+	assert.Equal(t, DefaultRefresh, config.Serve.Refresh)
+	require.NoError(t, config.AddRefresh("30s"))
+	assert.Equal(t, 30*time.Second, config.Serve.Refresh)
+	assert.Error(t, config.AddRefresh("10ms"), "Ожидалось, что выкинется ошибка для слишком частого обновления")
+
+	require.NoError(t, config.AddMaxRecords(""))
+	assert.Equal(t, DefaultMaxRecords, config.Serve.MaxRecords)
+	require.NoError(t, config.AddMaxRecords("5000"))
+	assert.Equal(t, 5000, config.Serve.MaxRecords)
+	assert.Error(t, config.AddMaxRecords("0"), "Ожидалось, что выкинется ошибка для нулевого числа записей")
+
+	require.NoError(t, config.AddLabelLimits("route=50, method=5"))
+	assert.Equal(t, map[string]int{LabelMethod: 5, LabelStatus: DefaultStatusLimit, LabelRoute: 50}, config.LabelLimits)
+
+	assert.Error(t, config.AddLabelLimits("path=10"), "Ожидалось, что выкинется ошибка для неизвестной метки")
+	assert.Error(t, config.AddLabelLimits("route=0"), "Ожидалось, что выкинется ошибка для нулевого ограничения")
+	assert.Error(t, config.AddLabelLimits("route"), "Ожидалось, что выкинется ошибка без ограничения")
+}
+
+func TestSyslogListeners(t *testing.T) {
+	config := &Config{}
+
+	require.NoError(t, config.AddSyslog("udp://:5514, tcp://127.0.0.1:5514"))
+	assert.Equal(t, []SyslogListener{{Network: SyslogUDP, Address: ":5514"}, {Network: SyslogTCP, Address: "127.0.0.1:5514"}},
+		config.Syslog)
+
+	assert.Error(t, (&Config{}).AddSyslog(":5514"), "Ожидалось, что выкинется ошибка без протокола")
+	assert.Error(t, (&Config{}).AddSyslog("unix:///dev/log"), "Ожидалось, что выкинется ошибка для неподдерживаемого протокола")
+	assert.Error(t, (&Config{}).AddSyslog("udp://5514"), "Ожидалось, что выкинется ошибка для адреса без порта")
+
+	config = &Config{Path: "access.log", TypePath: "local"}
+	assert.Error(t, config.AddSyslog("udp://:5514"), "Ожидалось, что выкинется ошибка при одновременном --path")
+}
+
+func TestStateSources(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "host1.state"), filepath.Join(dir, "host2.state")
+
+	require.NoError(t, os.WriteFile(first, []byte("{}"), 0o600))
+	require.NoError(t, os.WriteFile(second, []byte("{}"), 0o600))
+
+	t.Run("StateGlob", func(t *testing.T) {
+		config := &Config{}
+
+		require.NoError(t, config.AddSource(filepath.Join(dir, "*.state")))
+		assert.Equal(t, "state", config.TypePath)
+		assert.Equal(t, []string{first, second}, config.States)
+	})
+
+	t.Run("AddStates", func(t *testing.T) {
+		config := &Config{}
+
+		assert.Error(t, config.AddStates(nil), "Ожидалось, что выкинется ошибка без файлов состояния")
+		assert.Error(t, config.AddStates([]string{filepath.Join(dir, "missing.state")}),
+			"Ожидалось, что выкинется ошибка для несуществующего файла")
+	})
+
+	t.Run("EmitState", func(t *testing.T) {
+		config := &Config{}
+
+		require.NoError(t, config.AddEmitState(filepath.Join(dir, "out.state")))
+		assert.Equal(t, filepath.Join(dir, "out.state"), config.EmitState)
+		assert.Error(t, config.AddEmitState(filepath.Join(dir, "missing", "out.state")),
+			"Ожидалось, что выкинется ошибка для несуществующего каталога")
+	})
+}
+
+func TestAddCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "analyzer.checkpoint")
+
+	config := &Config{TypePath: "local"}
+
+	require.NoError(t, config.AddCheckpoint(path))
+	assert.Equal(t, path, config.Checkpoint)
+
+	assert.Error(t, (&Config{TypePath: "url"}).AddCheckpoint(path), "Ожидалось, что выкинется ошибка для логов по URL")
+	assert.Error(t, config.AddCheckpoint(filepath.Join(dir, "missing", "analyzer.checkpoint")),
+		"Ожидалось, что выкинется ошибка для несуществующего каталога")
+
+	config.Anonymize.Mode = AnonymizeTruncate
+	require.NoError(t, config.AddCheckpoint(path), "Усечение адресов не использует ключ")
+
+	config.Anonymize.Mode = AnonymizeHMAC
+	assert.Error(t, config.AddCheckpoint(path), "Ожидалось, что выкинется ошибка без постоянного ключа")
+}
+
+func TestRetentionOptions(t *testing.T) {
+	config := &Config{}
+
+	require.NoError(t, config.AddRetention("720h"))
+	require.NoError(t, config.AddDownsample("168h"))
+	assert.Equal(t, RetentionOptions{Keep: 720 * time.Hour, Downsample: 168 * time.Hour}, config.Retention)
+
+	assert.Error(t, config.AddDownsample("1000h"), "Ожидалось, что выкинется ошибка для прореживания позже удаления")
+	assert.Error(t, config.AddRetention("month"), "Ожидалось, что выкинется ошибка для некорректного срока")
+	assert.Error(t, config.AddStore(filepath.Join(t.TempDir(), "missing", "history.db")),
+		"Ожидалось, что выкинется ошибка для несуществующего каталога")
+}
+
+func TestExportOptions(t *testing.T) {
+	config := &Config{}
+	output := filepath.Join(t.TempDir(), "out.db")
+
+	require.NoError(t, config.AddExport(ExportSQLite, output))
+	require.NoError(t, config.AddExportMode(""))
+	assert.Equal(t, ExportOptions{Format: ExportSQLite, Output: output, Mode: ExportCreate}, config.Export)
+
+	assert.Error(t, config.AddExport("csv", output), "Ожидалось, что выкинется ошибка для неизвестного формата")
+	assert.Error(t, config.AddExport(ExportSQLite, ""), "Ожидалось, что выкинется ошибка без файла")
+	assert.Error(t, config.AddExportMode("merge"), "Ожидалось, что выкинется ошибка для неизвестного режима")
+	assert.Error(t, config.AddPartition(PartitionDate), "Ожидалось, что выкинется ошибка для разбиения в SQLite")
+	assert.Error(t, config.AddRowGroupSize("5000"), "Ожидалось, что выкинется ошибка для размера группы в SQLite")
+
+	require.NoError(t, config.AddExport(ExportParquet, filepath.Join(filepath.Dir(output), "records")))
+	require.NoError(t, config.AddRowGroupSize(""))
+	assert.Equal(t, DefaultRowGroupSize, config.Export.RowGroupSize)
+	require.NoError(t, config.AddRowGroupSize("5000"))
+	require.NoError(t, config.AddPartition(PartitionDate))
+	assert.Equal(t, 5000, config.Export.RowGroupSize)
+	assert.Equal(t, PartitionDate, config.Export.Partition)
+
+	assert.Error(t, config.AddRowGroupSize("10"), "Ожидалось, что выкинется ошибка для слишком маленькой группы")
+	assert.Error(t, config.AddPartition("hour"), "Ожидалось, что выкинется ошибка для неизвестного разбиения")
+}
@@ -0,0 +1,36 @@
+package domain
+
+const (
+	MetricCount    = "count"
+	MetricBytes    = "bytes"
+	MetricDuration = "duration"
+	MetricPercent  = "percent"
+)
+
+type ReportDiff struct {
+	Base            ReportSource
+	Current         ReportSource
+	Metrics         []MetricDelta
+	StatusCodes     []MetricDelta
+	NewResources    []ValueCount
+	GoneResources   []ValueCount
+	NewStatusCodes  []ValueCount
+	GoneStatusCodes []ValueCount
+	Regressions     []string
+}
+
+type ReportSource struct {
+	FileNames     []string
+	URLName       string
+	TotalRequests int
+}
+
+type MetricDelta struct {
+	Name          string
+	Kind          string
+	Base          float64
+	Current       float64
+	Delta         float64
+	ChangePercent *float64
+	Regression    bool
+}
@@ -13,4 +13,33 @@ type LogRecord struct {
 	BodyBytesSent   int
 	Referer         string
 	UserAgent       string
+	ForwardedFor    string
+	RealIP          string
+	PeerAddr        string
+	RequestTime     time.Duration
+	HasRequestTime  bool
+	Source          string
+	Offset          int64
+	Agent           UserAgent
+	Geo             Geo
+}
+
+type RecordBatches func(handle func(records []LogRecord) error) error
+
+type UserAgent struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	Device         string
+	Bot            bool
+	BotName        string
+}
+
+type Geo struct {
+	Country      string
+	CountryName  string
+	City         string
+	ASN          uint
+	Organization string
 }
@@ -7,7 +7,10 @@ type LogReport struct {
 	URLName                  string
 	StartDate                time.Time
 	EndDate                  time.Time
+	FirstRequest             time.Time
+	LastRequest              time.Time
 	TotalRequests            int
+	DroppedRecords           int
 	AvgBodySize              int
 	AvgTimeBetweenRequests   time.Duration
 	Percentile95Size         int
@@ -16,6 +19,21 @@ type LogReport struct {
 	ResponseCodes            map[int]ResponseCode
 	SortedResponseCodes      []int
 	TopIPAddresses           []IPCount
+	UserAgents               UserAgentStats
+	Referers                 RefererStats
+	StatusClasses            []StatusClass
+	ClientErrors             int
+	ServerErrors             int
+	BucketSize               time.Duration
+	Timeline                 []TimeBucket
+	Traffic                  TrafficStats
+	Anomalies                []Anomaly
+	Security                 SecurityStats
+	Sessions                 SessionStats
+	Funnel                   FunnelStats
+	Geo                      GeoStats
+	Profile                  *ClientProfile
+	Approximation            ApproxStats
 }
 
 type ResponseCode struct {
@@ -27,3 +45,136 @@ type IPCount struct {
 	IP    string
 	Count int
 }
+
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+type UserAgentStats struct {
+	TopBrowsers         []ValueCount
+	TopOperatingSystems []ValueCount
+	DeviceTypes         []ValueCount
+	TopBots             []ValueCount
+	TopAgents           []ValueCount
+	BotRequests         int
+	HumanRequests       int
+}
+
+type RefererStats struct {
+	DirectRequests   int
+	InternalRequests int
+	ExternalRequests int
+	TopDomains       []ValueCount
+	TopSearchEngines []ValueCount
+	TopLandingPairs  []RefererLanding
+}
+
+type RefererLanding struct {
+	Referer string
+	URL     string
+	Count   int
+}
+
+type StatusClass struct {
+	Class      string
+	Count      int
+	TopURLs    []ValueCount
+	TopClients []ValueCount
+}
+
+type TimeBucket struct {
+	Start             time.Time
+	Requests          int
+	ClientErrors      int
+	ServerErrors      int
+	Bytes             int64
+	TopClient         string
+	TopClientRequests int
+}
+
+type TrafficStats struct {
+	TotalBytes      int64
+	PeakBytes       int64
+	PeakBucketStart time.Time
+	TopURLs         []ByteCount
+	TopClients      []ByteCount
+}
+
+type ByteCount struct {
+	Value string
+	Bytes int64
+}
+
+type Anomaly struct {
+	Start       time.Time
+	End         time.Time
+	Metric      string
+	Kind        string
+	Expected    float64
+	Observed    float64
+	Description string
+}
+
+type GeoStats struct {
+	ResolvedRequests int
+	TopCountries     []ValueCount
+	TopNetworks      []ValueCount
+}
+
+type SecurityStats struct {
+	FlaggedRequests int
+	Signals         []ValueCount
+	Clients         []SecurityClient
+}
+
+type SecurityClient struct {
+	IP       string
+	Requests int
+	Signals  []ValueCount
+	Evidence []string
+}
+
+type SessionStats struct {
+	Gap            time.Duration
+	Sessions       int
+	Visitors       int
+	AvgDuration    time.Duration
+	AvgPages       float64
+	BounceSessions int
+	TopEntryURLs   []ValueCount
+	TopExitURLs    []ValueCount
+	TopPaths       []ValueCount
+}
+
+type FunnelStats struct {
+	Window time.Duration
+	Steps  []FunnelStep
+}
+
+type FunnelStep struct {
+	URL     string
+	Clients int
+}
+
+type ClientProfile struct {
+	Client     string
+	FirstSeen  time.Time
+	LastSeen   time.Time
+	Requests   int
+	Bytes      int64
+	Addresses  []ValueCount
+	Statuses   []ValueCount
+	Methods    []ValueCount
+	URLs       []ValueCount
+	UserAgents []ValueCount
+	BucketSize time.Duration
+	Timeline   []TimeBucket
+}
+
+type ApproxStats struct {
+	Capacity       int
+	IPError        int
+	ResourceError  int
+	UserAgentError int
+}
@@ -0,0 +1,95 @@
+package domain
+
+import "time"
+
+type Rollup struct {
+	Start        time.Time
+	Requests     int
+	FirstRequest time.Time
+	LastRequest  time.Time
+	Bytes        int64
+	BodySizes    map[int]int
+	Statuses     map[int]int
+	URLs         map[string]Usage
+	Clients      map[string]Usage
+}
+
+type Usage struct {
+	Requests int
+	Bytes    int64
+}
+
+type RetentionOptions struct {
+	Keep       time.Duration
+	Downsample time.Duration
+}
+
+func NewRollup(start time.Time) Rollup {
+	return Rollup{
+		Start:     start,
+		BodySizes: make(map[int]int),
+		Statuses:  make(map[int]int),
+		URLs:      make(map[string]Usage),
+		Clients:   make(map[string]Usage),
+	}
+}
+
+func (rollup *Rollup) Add(record *LogRecord) {
+	if rollup.Requests == 0 || record.TimeLocal.Before(rollup.FirstRequest) {
+		rollup.FirstRequest = record.TimeLocal
+	}
+
+	if record.TimeLocal.After(rollup.LastRequest) {
+		rollup.LastRequest = record.TimeLocal
+	}
+
+	bytes := int64(record.BodyBytesSent)
+
+	rollup.Requests++
+	rollup.Bytes += bytes
+	rollup.BodySizes[record.BodyBytesSent]++
+	rollup.Statuses[record.Status]++
+	addUsage(rollup.URLs, record.URL, Usage{Requests: 1, Bytes: bytes})
+	addUsage(rollup.Clients, record.RemoteAddr, Usage{Requests: 1, Bytes: bytes})
+}
+
+func (rollup *Rollup) Merge(other *Rollup) {
+	if other.Requests == 0 {
+		return
+	}
+
+	if rollup.Requests == 0 || other.FirstRequest.Before(rollup.FirstRequest) {
+		rollup.FirstRequest = other.FirstRequest
+	}
+
+	if other.LastRequest.After(rollup.LastRequest) {
+		rollup.LastRequest = other.LastRequest
+	}
+
+	rollup.Requests += other.Requests
+	rollup.Bytes += other.Bytes
+
+	for size, count := range other.BodySizes {
+		rollup.BodySizes[size] += count
+	}
+
+	for status, count := range other.Statuses {
+		rollup.Statuses[status] += count
+	}
+
+	mergeUsage(rollup.URLs, other.URLs)
+	mergeUsage(rollup.Clients, other.Clients)
+}
+
+func mergeUsage(target, source map[string]Usage) {
+	for key, usage := range source {
+		addUsage(target, key, usage)
+	}
+}
+
+func addUsage(target map[string]Usage, key string, usage Usage) {
+	total := target[key]
+	total.Requests += usage.Requests
+	total.Bytes += usage.Bytes
+	target[key] = total
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"encoding/json"
+	"net/netip"
+	"time"
+)
+
+const (
+	StateVersion      = 1
+	CheckpointVersion = 1
+)
+
+type AnalyzerState struct {
+	Version       int
+	Settings      StateSettings
+	FileNames     []string
+	URLNames      []string
+	TotalRequests int
+	Accumulators  []json.RawMessage
+}
+
+type StateSettings struct {
+	From          time.Time
+	To            time.Time
+	Approx        int
+	SiteHosts     []string
+	BucketSize    time.Duration
+	SessionGap    time.Duration
+	Funnel        []string
+	FunnelWindow  time.Duration
+	ProfileClient netip.Prefix
+	Anonymize     string
+
+	URL            URLSettings
+	ClientIP       string
+	TrustedProxies []netip.Prefix
+	UserAgentRules string
+}
+
+type URLSettings struct {
+	Lowercase   bool
+	Decode      bool
+	CollapseIDs bool
+	StripQuery  []string
+	KeepQuery   []string
+	Routes      []string
+}
+
+type Checkpoint struct {
+	Version int
+	Files   []FileCheckpoint
+	State   *AnalyzerState
+}
+
+type FileCheckpoint struct {
+	Path   string
+	Inode  uint64
+	Size   int64
+	Offset int64
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	NormalizeLowercase = "lowercase"
+	NormalizeDecode    = "decode"
+	NormalizeIDs       = "ids"
+	StripAllQuery      = "*"
+)
+
+type URLOptions struct {
+	Lowercase   bool
+	Decode      bool
+	CollapseIDs bool
+	StripQuery  []string
+	KeepQuery   []string
+	Routes      []Route
+}
+
+type Route struct {
+	Template string
+	Pattern  *regexp.Regexp
+}
+
+func (options *URLOptions) IsEmpty() bool {
+	return !options.Lowercase && !options.Decode && !options.CollapseIDs &&
+		len(options.StripQuery) == 0 && len(options.KeepQuery) == 0 && len(options.Routes) == 0
+}
+
+func NewRoute(template string) (Route, error) {
+	if !strings.HasPrefix(template, "/") {
+		return Route{}, fmt.Errorf("шаблон маршрута должен начинаться с '/': %s", template)
+	}
+
+	var pattern strings.Builder
+
+	pattern.WriteString("^")
+
+	segments := strings.Split(template, "/")
+	for i, segment := range segments {
+		if i > 0 {
+			pattern.WriteString("/")
+		}
+
+		switch {
+		case segment == "*" && i == len(segments)-1:
+			pattern.WriteString(".*")
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			pattern.WriteString("[^/]+")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(segment))
+		}
+	}
+
+	pattern.WriteString("/?$")
+
+	compiled, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return Route{}, fmt.Errorf("не удалась компиляция шаблона маршрута %s: %v", template, err)
+	}
+
+	return Route{Template: template, Pattern: compiled}, nil
+}
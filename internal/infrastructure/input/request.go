@@ -3,7 +3,7 @@ package input
 import (
 	"fmt"
 	"log"
-	"os"
+	"strings"
 
 	"github.com/vorduin/slices"
 )
@@ -15,7 +15,7 @@ type RequestTemplate struct {
 
 func checkFlags(pattern RequestTemplate, parts []string) error {
 	for _, word := range parts {
-		if word[0:2] == "--" {
+		if isFlag(word) {
 			if !slices.Contains(pattern.RequiredFlags, word[2:]) && !slices.Contains(pattern.OptionalFlags, word[2:]) {
 				return fmt.Errorf("неизвестный флаг: %s", word)
 			}
@@ -39,7 +39,7 @@ func checkCountFlags(pattern RequestTemplate, parts []string) error {
 	}
 
 	for i := 0; i < len(parts)-1; i += 2 {
-		if !(parts[i][0:2] == "--" && parts[i+1][0:2] != "--") {
+		if !(isFlag(parts[i]) && !isFlag(parts[i+1])) {
 			return fmt.Errorf("неверный запрос")
 		}
 	}
@@ -65,9 +65,47 @@ func getFlags(pattern RequestTemplate, parts []string) map[string]string {
 	return flags
 }
 
-func Request(pattern RequestTemplate) (configMap map[string]string) {
-	parts := os.Args[1:]
+func isFlag(word string) bool {
+	return strings.HasPrefix(word, "--")
+}
+
+func Command(args []string) (command string, parts []string) {
+	if len(args) > 0 && !isFlag(args[0]) {
+		return args[0], args[1:]
+	}
+
+	return "", args
+}
+
+func Positional(args []string) (positional, parts []string) {
+	for ind, word := range args {
+		if isFlag(word) {
+			return args[:ind], args[ind:]
+		}
+	}
+
+	return args, nil
+}
+
+func SplitPositional(args []string) (positional, parts []string) {
+	for ind := 0; ind < len(args); ind++ {
+		if !isFlag(args[ind]) {
+			positional = append(positional, args[ind])
+			continue
+		}
+
+		parts = append(parts, args[ind])
+
+		if ind+1 < len(args) && !isFlag(args[ind+1]) {
+			ind++
+			parts = append(parts, args[ind])
+		}
+	}
+
+	return positional, parts
+}
 
+func Request(pattern RequestTemplate, parts []string) (configMap map[string]string) {
 	err := checkFlags(pattern, parts)
 	if err != nil {
 		log.Fatalf("Ошибка: %v", err)
@@ -60,3 +60,34 @@ func TestGetFlags_Valid(t *testing.T) {
 	assert.Equal(t, "2022-01-01", flags["from"])
 	assert.Empty(t, flags["to"])
 }
+
+func TestCommand(t *testing.T) {
+	command, parts := Command([]string{"diff", "--base", "a.json", "--current", "b.log"})
+
+	assert.Equal(t, "diff", command)
+	assert.Equal(t, []string{"--base", "a.json", "--current", "b.log"}, parts)
+
+	command, parts = Command([]string{"--path", "b.log"})
+
+	assert.Empty(t, command)
+	assert.Equal(t, []string{"--path", "b.log"}, parts)
+}
+
+func TestPositional(t *testing.T) {
+	positional, parts := Positional([]string{"host1.state", "host2.state", "--format", "adoc"})
+
+	assert.Equal(t, []string{"host1.state", "host2.state"}, positional)
+	assert.Equal(t, []string{"--format", "adoc"}, parts)
+
+	positional, parts = Positional([]string{"host1.state"})
+
+	assert.Equal(t, []string{"host1.state"}, positional)
+	assert.Empty(t, parts)
+}
+
+func TestSplitPositional(t *testing.T) {
+	positional, parts := SplitPositional([]string{"--to", "sqlite", "out.db", "--path", "access.log"})
+
+	assert.Equal(t, []string{"out.db"}, positional)
+	assert.Equal(t, []string{"--to", "sqlite", "--path", "access.log"}, parts)
+}
@@ -0,0 +1,57 @@
+package lru
+
+import "container/list"
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type Cache[K comparable, V any] struct {
+	capacity int
+	order    *list.List
+	items    map[K]*list.Element
+}
+
+func New[K comparable, V any](capacity int) *Cache[K, V] {
+	capacity = max(capacity, 1)
+
+	return &Cache[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[K]*list.Element, capacity),
+	}
+}
+
+func (cache *Cache[K, V]) Get(key K) (V, bool) {
+	element, exists := cache.items[key]
+	if !exists {
+		var zero V
+		return zero, false
+	}
+
+	cache.order.MoveToFront(element)
+
+	return element.Value.(*entry[K, V]).value, true
+}
+
+func (cache *Cache[K, V]) Add(key K, value V) {
+	if element, exists := cache.items[key]; exists {
+		element.Value.(*entry[K, V]).value = value
+		cache.order.MoveToFront(element)
+
+		return
+	}
+
+	if cache.order.Len() >= cache.capacity {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.items, oldest.Value.(*entry[K, V]).key)
+	}
+
+	cache.items[key] = cache.order.PushFront(&entry[K, V]{key: key, value: value})
+}
+
+func (cache *Cache[K, V]) Len() int {
+	return cache.order.Len()
+}
@@ -0,0 +1,31 @@
+package lru
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	cache := New[string, int](2)
+
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	cache.Add("c", 3)
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "Самый давно использованный ключ вытесняется")
+	assert.Equal(t, 2, cache.Len())
+
+	cache.Add("a", 10)
+	value, _ = cache.Get("a")
+	assert.Equal(t, 10, value)
+
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+}
@@ -0,0 +1,31 @@
+package output
+
+import "fmt"
+
+var byteUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+func FormatBytes[T Integer](num T) string {
+	if float64(num) < 1024 {
+		return FormatNumber(num) + " B"
+	}
+
+	return formatByteUnits(float64(num))
+}
+
+func FormatByteRate(bytesPerSecond float64) string {
+	if bytesPerSecond < 1024 {
+		return fmt.Sprintf("%.2f B/s", bytesPerSecond)
+	}
+
+	return formatByteUnits(bytesPerSecond) + "/s"
+}
+
+func formatByteUnits(value float64) string {
+	unit := -1
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+
+	return fmt.Sprintf("%.2f %s", value, byteUnits[unit])
+}
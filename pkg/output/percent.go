@@ -0,0 +1,11 @@
+package output
+
+import "fmt"
+
+func FormatPercent[T Integer](part, total T) string {
+	if total == 0 {
+		return "0.00%"
+	}
+
+	return fmt.Sprintf("%.2f%%", float64(part)*100/float64(total))
+}
@@ -0,0 +1,201 @@
+package topk
+
+import (
+	"container/heap"
+	"encoding/json"
+	"sort"
+)
+
+type Item struct {
+	Key   string
+	Count int
+	Error int
+}
+
+type SpaceSaving struct {
+	capacity int
+	total    int
+	entries  *entryHeap
+}
+
+func NewSpaceSaving(capacity int) *SpaceSaving {
+	capacity = max(capacity, 1)
+
+	return &SpaceSaving{
+		capacity: capacity,
+		entries: &entryHeap{
+			items: make([]Item, 0, capacity),
+			index: make(map[string]int, capacity),
+		},
+	}
+}
+
+func (summary *SpaceSaving) Add(key string, count int) {
+	summary.total += count
+	entries := summary.entries
+
+	if position, exists := entries.index[key]; exists {
+		entries.items[position].Count += count
+		heap.Fix(entries, position)
+
+		return
+	}
+
+	if len(entries.items) < summary.capacity {
+		heap.Push(entries, Item{Key: key, Count: count})
+		return
+	}
+
+	minimum := entries.items[0]
+
+	delete(entries.index, minimum.Key)
+	entries.items[0] = Item{Key: key, Count: minimum.Count + count, Error: minimum.Count}
+	entries.index[key] = 0
+	heap.Fix(entries, 0)
+}
+
+func (summary *SpaceSaving) Merge(other *SpaceSaving) {
+	offset, otherOffset := summary.MaxError(), other.MaxError()
+	combined := make(map[string]Item, len(summary.entries.items)+len(other.entries.items))
+
+	for _, item := range summary.entries.items {
+		combined[item.Key] = Item{Key: item.Key, Count: item.Count + otherOffset, Error: item.Error + otherOffset}
+	}
+
+	for _, item := range other.entries.items {
+		if existing, exists := combined[item.Key]; exists {
+			existing.Count += item.Count - otherOffset
+			existing.Error += item.Error - otherOffset
+			combined[item.Key] = existing
+
+			continue
+		}
+
+		combined[item.Key] = Item{Key: item.Key, Count: item.Count + offset, Error: item.Error + offset}
+	}
+
+	items := make([]Item, 0, len(combined))
+	for _, item := range combined {
+		items = append(items, item)
+	}
+
+	summary.total += other.total
+	summary.entries.items = summary.entries.items[:0]
+	clear(summary.entries.index)
+
+	for _, item := range sortItems(items, summary.capacity) {
+		heap.Push(summary.entries, item)
+	}
+}
+
+func (summary *SpaceSaving) Top(limit int) []Item {
+	items := make([]Item, len(summary.entries.items))
+	copy(items, summary.entries.items)
+
+	return sortItems(items, limit)
+}
+
+func (summary *SpaceSaving) Counts() map[string]int {
+	counts := make(map[string]int, len(summary.entries.items))
+	for _, item := range summary.entries.items {
+		counts[item.Key] = item.Count
+	}
+
+	return counts
+}
+
+func (summary *SpaceSaving) Total() int {
+	return summary.total
+}
+
+func (summary *SpaceSaving) MaxError() int {
+	if len(summary.entries.items) < summary.capacity {
+		return 0
+	}
+
+	return summary.entries.items[0].Count
+}
+
+func (summary *SpaceSaving) MarshalJSON() ([]byte, error) {
+	return json.Marshal(spaceSavingState{
+		Capacity: summary.capacity,
+		Total:    summary.total,
+		Items:    summary.Top(0),
+	})
+}
+
+func (summary *SpaceSaving) UnmarshalJSON(data []byte) error {
+	var state spaceSavingState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	restored := NewSpaceSaving(state.Capacity)
+	restored.total = state.Total
+
+	for _, item := range sortItems(state.Items, restored.capacity) {
+		heap.Push(restored.entries, item)
+	}
+
+	*summary = *restored
+
+	return nil
+}
+
+type spaceSavingState struct {
+	Capacity int    `json:"capacity"`
+	Total    int    `json:"total"`
+	Items    []Item `json:"items"`
+}
+
+func sortItems(items []Item, limit int) []Item {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+
+		return items[i].Key < items[j].Key
+	})
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	return items
+}
+
+type entryHeap struct {
+	items []Item
+	index map[string]int
+}
+
+func (entries *entryHeap) Len() int {
+	return len(entries.items)
+}
+
+func (entries *entryHeap) Less(i, j int) bool {
+	return entries.items[i].Count < entries.items[j].Count
+}
+
+func (entries *entryHeap) Swap(i, j int) {
+	entries.items[i], entries.items[j] = entries.items[j], entries.items[i]
+	entries.index[entries.items[i].Key] = i
+	entries.index[entries.items[j].Key] = j
+}
+
+func (entries *entryHeap) Push(value any) {
+	item := value.(Item)
+
+	entries.index[item.Key] = len(entries.items)
+	entries.items = append(entries.items, item)
+}
+
+func (entries *entryHeap) Pop() any {
+	item := entries.items[len(entries.items)-1]
+
+	entries.items = entries.items[:len(entries.items)-1]
+	delete(entries.index, item.Key)
+
+	return item
+}
@@ -0,0 +1,108 @@
+package topk
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpaceSaving_Exact(t *testing.T) {
+	summary := NewSpaceSaving(3)
+
+	for _, key := range []string{"a", "b", "a", "c", "a", "b"} {
+		summary.Add(key, 1)
+	}
+
+	assert.Equal(t, []Item{{Key: "a", Count: 3}, {Key: "b", Count: 2}, {Key: "c", Count: 1}}, summary.Top(0))
+	assert.Equal(t, 6, summary.Total())
+	assert.Equal(t, 1, summary.MaxError())
+}
+
+func TestSpaceSaving_ErrorBound(t *testing.T) {
+	const capacity = 20
+
+	summary := NewSpaceSaving(capacity)
+	exact := make(map[string]int)
+
+	for i := range 10_000 {
+		key := fmt.Sprintf("noise-%d", i)
+		if i%5 == 0 {
+			key = fmt.Sprintf("heavy-%d", i%3)
+		}
+
+		summary.Add(key, 1)
+		exact[key]++
+	}
+
+	bound := summary.Total() / capacity
+
+	assert.LessOrEqual(t, summary.MaxError(), bound)
+
+	top := summary.Top(3)
+	require.Len(t, top, 3)
+
+	for _, item := range top {
+		assert.Contains(t, item.Key, "heavy-")
+		assert.GreaterOrEqual(t, item.Count, exact[item.Key])
+		assert.LessOrEqual(t, item.Count-item.Error, exact[item.Key])
+		assert.LessOrEqual(t, item.Error, bound)
+	}
+
+	assert.Len(t, summary.Counts(), capacity)
+}
+
+func TestSpaceSaving_Merge(t *testing.T) {
+	const capacity = 20
+
+	first, second := NewSpaceSaving(capacity), NewSpaceSaving(capacity)
+	exact := make(map[string]int)
+
+	for i := range 10_000 {
+		key := fmt.Sprintf("noise-%d", i)
+		if i%5 == 0 {
+			key = fmt.Sprintf("heavy-%d", i%3)
+		}
+
+		summary := first
+		if i%2 == 0 {
+			summary = second
+		}
+
+		summary.Add(key, 1)
+		exact[key]++
+	}
+
+	first.Merge(second)
+
+	assert.Equal(t, 10_000, first.Total())
+	assert.Len(t, first.Counts(), capacity)
+
+	for _, item := range first.Top(3) {
+		assert.Contains(t, item.Key, "heavy-")
+		assert.GreaterOrEqual(t, item.Count, exact[item.Key])
+		assert.LessOrEqual(t, item.Count-item.Error, exact[item.Key])
+	}
+
+	assert.LessOrEqual(t, first.MaxError(), first.Total()/capacity)
+}
+
+func TestSpaceSaving_JSON(t *testing.T) {
+	summary := NewSpaceSaving(3)
+
+	for _, key := range []string{"a", "b", "a", "c", "d", "a", "b"} {
+		summary.Add(key, 1)
+	}
+
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+
+	restored := NewSpaceSaving(1)
+	require.NoError(t, json.Unmarshal(data, restored))
+
+	assert.Equal(t, summary.Top(0), restored.Top(0))
+	assert.Equal(t, summary.Total(), restored.Total())
+	assert.Equal(t, summary.MaxError(), restored.MaxError())
+}